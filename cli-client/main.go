@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"strings"
 
@@ -16,14 +17,72 @@ type Message struct {
 	Payload interface{} `json:"payload"`
 }
 
+// RoomSummary is one entry from the server's GET /api/rooms.
+type RoomSummary struct {
+	ID          string `json:"id"`
+	State       string `json:"state"`
+	Mode        string `json:"mode"`
+	Players     int    `json:"players"`
+	MaxPlayers  int    `json:"maxPlayers"`
+	Host        string `json:"host"`
+	HasPasscode bool   `json:"hasPasscode"`
+}
+
+// roomsURL turns the websocket URL into the room browser's HTTP URL.
+func roomsURL(serverURL string) string {
+	base := strings.TrimSuffix(strings.TrimSuffix(serverURL, "/"), "/ws")
+	base = strings.Replace(base, "ws://", "http://", 1)
+	base = strings.Replace(base, "wss://", "https://", 1)
+	return base + "/api/rooms"
+}
+
+// listRooms prints the public rooms on the server.
+func listRooms(serverURL string) error {
+	resp, err := http.Get(roomsURL(serverURL))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server answered %s", resp.Status)
+	}
+
+	var body struct {
+		Rooms []RoomSummary `json:"rooms"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return err
+	}
+	if len(body.Rooms) == 0 {
+		fmt.Println("No open rooms. Start one by connecting without a room ID.")
+		return nil
+	}
+	fmt.Printf("%-10s %-8s %-13s %-8s %s\n", "ROOM", "STATE", "MODE", "PLAYERS", "HOST")
+	for _, r := range body.Rooms {
+		lock := ""
+		if r.HasPasscode {
+			lock = " 🔒"
+		}
+		fmt.Printf("%-10s %-8s %-13s %d/%-6d %s%s\n", r.ID, r.State, r.Mode, r.Players, r.MaxPlayers, r.Host, lock)
+	}
+	return nil
+}
+
 func main() {
 	if len(os.Args) < 2 {
 		fmt.Println("Usage: cli-client <server-url> [player-name] [room-id]")
+		fmt.Println("       cli-client <server-url> --rooms")
 		fmt.Println("Example: cli-client ws://localhost:8080/ws Alice room123")
 		os.Exit(1)
 	}
 
 	serverURL := os.Args[1]
+	if len(os.Args) > 2 && os.Args[2] == "--rooms" {
+		if err := listRooms(serverURL); err != nil {
+			log.Fatalf("Listing rooms failed: %v", err)
+		}
+		return
+	}
 	playerName := "Guest"
 	roomID := ""
 
@@ -89,7 +148,9 @@ func main() {
 	fmt.Println("  /guess <answer>         - Make a guess")
 	fmt.Println("  /start                  - Start game")
 	fmt.Println("  /status                 - Get game status")
-	fmt.Println("  /quit                   - Exit\n")
+	fmt.Println("  /rooms                  - List open rooms")
+	fmt.Println("  /quit                   - Exit")
+	fmt.Println()
 
 	for {
 		fmt.Print("> ")
@@ -117,6 +178,14 @@ func main() {
 		var action Message
 
 		switch cmd {
+		case "/rooms":
+			if err := listRooms(serverURL); err != nil {
+				fmt.Printf("Listing rooms failed: %v\n", err)
+			} else {
+				fmt.Printf("Join one with: cli-client %s %s <room-id>\n", serverURL, playerName)
+			}
+			continue
+
 		case "/join-room":
 			if len(parts) < 2 {
 				fmt.Println("Usage: /join-room <room-id>")
@@ -154,7 +223,7 @@ func main() {
 			}
 
 		default:
-			fmt.Println("Unknown command. Use /join-room, /guess, /start, /status, or /quit")
+			fmt.Println("Unknown command. Use /join-room, /guess, /start, /status, /rooms, or /quit")
 			continue
 		}
 
@@ -1,54 +1,147 @@
 package game
 
 import (
+	"context"
 	"log"
+	"math"
+	"math/rand/v2"
 	"strings"
+	"sync"
+)
+
+// BotDifficulty sets how well a bot plays.
+type BotDifficulty string
 
-	"github.com/mr-destructive/meta-ai-golang"
+const (
+	// BotEasy sometimes gives up and favours short, well-known places.
+	BotEasy BotDifficulty = "EASY"
+	// BotMedium plays any valid place at random.
+	BotMedium BotDifficulty = "MEDIUM"
+	// BotHard steers opponents onto letters with few answers left.
+	BotHard BotDifficulty = "HARD"
 )
 
-type Bot struct {
-	Dict   *Dictionary
-	MetaAI *meta_ai.MetaAI
+// easyMissChance is how often an EASY bot fails to think of anything.
+const easyMissChance = 0.2
+
+// ParseBotDifficulty maps a client-supplied level to a difficulty, defaulting
+// to MEDIUM for anything unrecognised.
+func ParseBotDifficulty(s string) BotDifficulty {
+	switch d := BotDifficulty(strings.ToUpper(strings.TrimSpace(s))); d {
+	case BotEasy, BotHard:
+		return d
+	default:
+		return BotMedium
+	}
+}
+
+// BotTurn is everything a strategy needs to know to answer one turn.
+type BotTurn struct {
+	LastWord  string
+	UsedWords map[string]bool // Normalized keys; safe for the strategy to read
+	Level     BotDifficulty
+	Dict      *Dictionary
+	Filter    PlaceFilter // Places outside it don't count this game
 }
 
-func NewBot(d *Dictionary) *Bot {
-	// Initialize Meta AI
-	m, err := meta_ai.NewMetaAI("", "", nil)
-	if err != nil {
-		log.Printf("[Bot] Meta AI Init Error: %v", err)
+// TargetLetter is the letter the answer must start with.
+func (t BotTurn) TargetLetter() rune {
+	if last := LastLetter(t.LastWord); last != 0 {
+		return last
 	}
+	return 'a'
+}
+
+// BotStrategy decides a bot's answer. An empty PlaceInfo means the bot gives
+// up this turn. Implementations must honour ctx so a slow answer can be
+// abandoned.
+type BotStrategy interface {
+	GetMove(ctx context.Context, turn BotTurn) PlaceInfo
+}
+
+// Bot is the default strategy: it plays straight from the dictionary. One
+// Bot serves every bot in a room, and an abandoned turn may still be running
+// when the next starts, so Rand is only used with mu held.
+type Bot struct {
+	// Rand drives word choice; replace it with a fixed seed for
+	// reproducible games.
+	Rand *rand.Rand
+	mu   sync.Mutex
+}
+
+func NewBot() *Bot {
 	return &Bot{
-		Dict:   d,
-		MetaAI: m,
+		Rand: rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
 	}
 }
 
-func (b *Bot) GetMove(lastWord string, usedWords map[string]bool) PlaceInfo {
-	var targetLetter string
-	if lastWord == "" {
-		targetLetter = "a"
-	} else {
-		targetLetter = strings.ToLower(string(lastWord[len(lastWord)-1]))
+// GetMove picks the bot's answer from the dictionary at the turn's
+// difficulty. Once ctx is cancelled it stops weighing places and gives up.
+func (b *Bot) GetMove(ctx context.Context, turn BotTurn) PlaceInfo {
+	if ctx.Err() != nil {
+		return PlaceInfo{}
 	}
+	letter := turn.TargetLetter()
 
-	// 1. Try LLM (Placeholder fix: using unexported prompt logic simulation or assuming an exported method exists)
-	// For now, I'll stick to Dictionary to ensure it BUILDS. 
-	// If you want to use MetaAI, we need to find the correct exported method.
-	
-	// log.Printf("[Bot] LLM Attempt for letter %s", targetLetter)
-	// if b.MetaAI != nil {
-	//    // If the library had a 'Chat' or 'Prompt' method...
-	// }
+	b.mu.Lock()
+	if turn.Level == BotEasy && b.Rand.Float64() < easyMissChance {
+		b.mu.Unlock()
+		log.Printf("[Bot] Easy bot drew a blank on letter: %c", letter)
+		return PlaceInfo{}
+	}
+	move := turn.Dict.PickUnusedStartingWith(letter, turn.UsedWords, b.Rand, b.weigh(ctx, turn))
+	b.mu.Unlock()
+	if ctx.Err() != nil {
+		return PlaceInfo{} // Abandoned mid-pick; the answer may be partial
+	}
 
-	// 2. Fallback to Rule-Based (Dictionary)
-	move := b.Dict.GetUnusedPlaceStartingWith(targetLetter[0], usedWords)
-	
 	if move.Name != "" {
 		log.Printf("[Bot] Found word in dictionary: %s (%s)", move.Name, move.Type)
 	} else {
-		log.Printf("[Bot] No valid words found for letter: %s", targetLetter)
+		log.Printf("[Bot] No valid words found for letter: %c", letter)
 	}
 
 	return move
 }
+
+// weigh returns the selection weight for a place at the turn's difficulty, or
+// nil for a uniform pick. Places outside the game's filter weigh nothing, as
+// does every place once ctx is cancelled.
+func (b *Bot) weigh(ctx context.Context, turn BotTurn) func(PlaceInfo, rune) float64 {
+	var score func(PlaceInfo, rune) float64
+	switch turn.Level {
+	case BotEasy:
+		// Short names of big places: what a casual player would think of
+		score = func(p PlaceInfo, _ rune) float64 {
+			return (1 + math.Log10(float64(p.Population)+1)) / math.Pow(float64(len(p.Name)), 2)
+		}
+	case BotHard:
+		// Fewer answers for the next player's letter is better; cubed so
+		// the bot almost always takes the nastiest option. Only places the
+		// filter allows are answers the next player could give. This
+		// runs inside the pick, under the dictionary's read lock.
+		remaining := make(map[rune]float64)
+		score = func(p PlaceInfo, last rune) float64 {
+			n, ok := remaining[last]
+			if !ok {
+				n = float64(turn.Dict.countStartingWithInLocked(last, turn.UsedWords, turn.Filter))
+				remaining[last] = n
+			}
+			return 1 / math.Pow(1+n, 3)
+		}
+	}
+
+	filter := turn.Filter
+	if score == nil && filter.IsZero() {
+		return nil
+	}
+	return func(p PlaceInfo, last rune) float64 {
+		if ctx.Err() != nil || !filter.Matches(p) {
+			return 0
+		}
+		if score == nil {
+			return 1
+		}
+		return score(p, last)
+	}
+}
@@ -0,0 +1,155 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHardBotTrapsThroughFilter(t *testing.T) {
+	dict := testDictionary(t,
+		PlaceInfo{Name: "Omania", Type: "Country"},
+		PlaceInfo{Name: "Oxland", Type: "Country"},
+		PlaceInfo{Name: "Alpha", Type: "Country"},
+		PlaceInfo{Name: "Atlantis", Type: "Country"},
+		PlaceInfo{Name: "Avalon", Type: "Country"},
+		PlaceInfo{Name: "Dania", Type: "Country"},
+		PlaceInfo{Name: "Dover", Type: "City"},
+		PlaceInfo{Name: "Delhi", Type: "City"},
+		PlaceInfo{Name: "Dakar", Type: "City"},
+		PlaceInfo{Name: "Denver", Type: "City"},
+	)
+
+	tests := []struct {
+		name   string
+		filter PlaceFilter
+		want   string
+	}{
+		// Five places start with D but only three with A
+		{"unfiltered", PlaceFilter{}, "Omania"},
+		// Of those, only one D is a country
+		{"countries only", PlaceFilter{Types: []string{"Country"}}, "Oxland"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			turn := BotTurn{LastWord: "Toronto", UsedWords: map[string]bool{}, Level: BotHard, Dict: dict, Filter: tt.filter}
+			// No rng: the heaviest place wins
+			got := dict.PickUnusedStartingWith('o', turn.UsedWords, nil, NewBot().weigh(context.Background(), turn))
+			if got.Name != tt.want {
+				t.Errorf("HARD bot played %q, want %q", got.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestBotConcurrentMoves(t *testing.T) {
+	dict := testDictionary(t,
+		PlaceInfo{Name: "Oslo", Type: "City"},
+		PlaceInfo{Name: "Ottawa", Type: "City"},
+		PlaceInfo{Name: "Osaka", Type: "City"},
+	)
+	bot := NewBot()
+
+	// An abandoned turn can still be thinking when the next one starts;
+	// run under -race to catch unsynchronised use of the shared rng
+	var wg sync.WaitGroup
+	for _, level := range []BotDifficulty{BotEasy, BotMedium, BotHard, BotMedium} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				bot.GetMove(context.Background(), BotTurn{LastWord: "Toronto", UsedWords: map[string]bool{}, Level: level, Dict: dict})
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestBotGivesUpWhenCancelled(t *testing.T) {
+	dict := testDictionary(t,
+		PlaceInfo{Name: "Oslo", Type: "City"},
+		PlaceInfo{Name: "Ottawa", Type: "City"},
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, level := range []BotDifficulty{BotEasy, BotMedium, BotHard} {
+		turn := BotTurn{LastWord: "Toronto", UsedWords: map[string]bool{}, Level: level, Dict: dict}
+		if got := NewBot().GetMove(ctx, turn); got.Name != "" {
+			t.Errorf("%s bot answered %s after its turn was abandoned", level, got.Name)
+		}
+		// A turn abandoned mid-pick stops weighing what's left
+		if weight := NewBot().weigh(ctx, turn); weight != nil && weight(PlaceInfo{Name: "Oslo", Type: "City"}, 'o') != 0 {
+			t.Errorf("%s bot still weighs places after its turn was abandoned", level)
+		}
+	}
+}
+
+func TestHardBotDuringReload(t *testing.T) {
+	// A big bucket with answers ending in every letter keeps each pick
+	// under the read lock long enough for a reload to queue behind it
+	var places []PlaceInfo
+	for i := range 2000 {
+		name := fmt.Sprintf("O%d%c", i, 'a'+rune(i%26))
+		places = append(places, PlaceInfo{Name: name, Type: "City"})
+	}
+	dict := testDictionary(t, places...)
+	bot := NewBot()
+
+	// HARD weighs each answer by counting the next letter's places while
+	// the pick holds the read lock; a reload queued for the write lock in
+	// between must not wedge either side
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var wg sync.WaitGroup
+		for _, filter := range []PlaceFilter{{}, {Types: []string{"City"}}} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range 50 {
+					bot.GetMove(context.Background(), BotTurn{LastWord: "Toronto", UsedWords: map[string]bool{}, Level: BotHard, Dict: dict, Filter: filter})
+				}
+			}()
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				if err := dict.Reload(); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+		wg.Wait()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("HARD bots and a reload deadlocked")
+	}
+}
+
+func TestObscurity(t *testing.T) {
+	tests := []struct {
+		population int64
+		want       float64
+	}{
+		{20_000_000, 0},
+		{10_000_000, 0},
+		{1, 1},
+		{0, 0.5}, // Unknown
+	}
+	for _, tt := range tests {
+		if got := obscurity(PlaceInfo{Population: tt.population}); got != tt.want {
+			t.Errorf("obscurity(population %d) = %v, want %v", tt.population, got, tt.want)
+		}
+	}
+	if town, city := obscurity(PlaceInfo{Population: 5_000}), obscurity(PlaceInfo{Population: 2_000_000}); town <= city {
+		t.Errorf("a town (%v) should be more obscure than a city (%v)", town, city)
+	}
+}
@@ -0,0 +1,69 @@
+package game
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+	"unicode/utf8"
+)
+
+// botPace is how a difficulty level thinks and types.
+type botPace struct {
+	think   time.Duration // Time to come up with an easy answer
+	perChar time.Duration // Typing speed
+}
+
+var botPaces = map[BotDifficulty]botPace{
+	BotEasy:   {think: 3500 * time.Millisecond, perChar: 180 * time.Millisecond},
+	BotMedium: {think: 2500 * time.Millisecond, perChar: 120 * time.Millisecond},
+	BotHard:   {think: 1500 * time.Millisecond, perChar: 80 * time.Millisecond},
+}
+
+// scarceAnswers is the number of remaining answers below which a letter
+// starts to make a bot hesitate.
+const scarceAnswers = 25
+
+// famousPopulation is the population from which a place comes to mind at
+// once; smaller places take longer to recall.
+const famousPopulation = 10_000_000
+
+// obscurity rates how hard p is to think of, from 0 for a household name to
+// 1 for a hamlet. Places without a known population are rated in between.
+func obscurity(p PlaceInfo) float64 {
+	if p.Population <= 0 {
+		return 0.5
+	}
+	fame := math.Log10(float64(p.Population)) / math.Log10(famousPopulation)
+	return 1 - min(max(fame, 0), 1)
+}
+
+// botThinkTime models how long a bot takes to play move: thinking time grows
+// with how obscure the place is and as the letter runs short of answers,
+// typing time grows with the word's length, and the total is jittered so
+// bots don't feel mechanical. A bot that gives up stalls for a while first,
+// as a stuck human would.
+func botThinkTime(turn BotTurn, move PlaceInfo) time.Duration {
+	pace, ok := botPaces[turn.Level]
+	if !ok {
+		pace = botPaces[BotMedium]
+	}
+
+	if move.Name == "" {
+		return jitter(3 * pace.think)
+	}
+
+	think := pace.think
+	think += time.Duration(obscurity(move) * float64(2*pace.think))
+	if remaining := turn.Dict.CountStartingWithIn(turn.TargetLetter(), turn.UsedWords, turn.Filter); remaining < scarceAnswers {
+		scarcity := float64(scarceAnswers-remaining) / scarceAnswers
+		think += time.Duration(scarcity * float64(2*pace.think))
+	}
+	typing := time.Duration(utf8.RuneCountInString(move.Name)) * pace.perChar
+
+	return jitter(think + typing)
+}
+
+// jitter scales d by a random factor between 0.7 and 1.3.
+func jitter(d time.Duration) time.Duration {
+	return time.Duration(float64(d) * (0.7 + 0.6*rand.Float64()))
+}
@@ -2,26 +2,68 @@ package game
 
 import (
 	"encoding/json"
+	"math/rand/v2"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 )
 
 type PlaceInfo struct {
-	Name string `json:"name"`
-	Type string `json:"type"`
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Aliases     []string `json:"aliases,omitempty"`     // Exonyms, former names, alternate spellings
+	CountryCode string   `json:"countryCode,omitempty"` // ISO 3166-1 alpha-2
+	Admin       string   `json:"admin,omitempty"`       // Parent region: state for a city, country for a state, continent for a country
+	Continent   string   `json:"continent,omitempty"`   // Filled in on load from the place's country when missing
+	Lat         float64  `json:"lat,omitempty"`
+	Lon         float64  `json:"lon,omitempty"`
+	Population  int64    `json:"population,omitempty"`
+	Capital     bool     `json:"capital,omitempty"` // National capital
 }
 
+// placeTypeRank orders entries that share a name; the highest tier is what a
+// bare name resolves to when no type is asked for.
+var placeTypeRank = map[string]int{
+	"City":      1,
+	"State":     2,
+	"Country":   3,
+	"Continent": 4,
+}
+
+// Dictionary keys every place by its NormalizePlace form so that accents,
+// case and punctuation don't affect matching. PlaceInfo.Name keeps the
+// canonical display spelling. A name may have several entries of different
+// types ("Georgia" the country and the US state), highest tier first.
 type Dictionary struct {
-	places map[string]PlaceInfo
-	mu     sync.RWMutex
+	path    string
+	places  map[string][]PlaceInfo
+	aliases map[string]string // normalized alias -> normalized canonical name
+	// Sorted normalized keys bucketed by first and last letter, so bots and
+	// hints only look at places that can actually be played.
+	byFirst map[rune][]string
+	byLast  map[rune][]string
+	// Normalized aliases by first letter, for suggestions
+	aliasesByFirst map[rune][]string
+	mu             sync.RWMutex
 }
 
 func NewDictionary(filepath string) (*Dictionary, error) {
-	data, err := os.ReadFile(filepath)
-	if err != nil {
+	d := &Dictionary{path: filepath}
+	if err := d.Reload(); err != nil {
 		return nil, err
 	}
+	return d, nil
+}
+
+// Reload re-reads the dictionary's file and swaps in the new data in one step,
+// so in-flight games see either the old or the new places, never a mix. On
+// error the current data is kept.
+func (d *Dictionary) Reload() error {
+	data, err := os.ReadFile(d.path)
+	if err != nil {
+		return err
+	}
 
 	var placeList []PlaceInfo
 	// Try parsing as array of objects
@@ -33,24 +75,199 @@ func NewDictionary(filepath string) (*Dictionary, error) {
 				placeList = append(placeList, PlaceInfo{Name: s, Type: "Place"})
 			}
 		} else {
-			return nil, err
+			return err
 		}
 	}
 
-	places := make(map[string]PlaceInfo)
+	// One entry per name and type; a repeated name+type replaces the earlier one
+	places := make(map[string][]PlaceInfo)
 	for _, p := range placeList {
-		places[strings.ToLower(p.Name)] = p
+		key := NormalizePlace(p.Name)
+		entries := places[key]
+		replaced := false
+		for i, e := range entries {
+			if e.Type == p.Type {
+				entries[i] = p
+				replaced = true
+			}
+		}
+		if !replaced {
+			entries = append(entries, p)
+		}
+		places[key] = entries
+	}
+	for _, entries := range places {
+		sort.SliceStable(entries, func(i, j int) bool {
+			return placeTypeRank[entries[i].Type] > placeTypeRank[entries[j].Type]
+		})
+	}
+	fillContinents(places)
+
+	// Aliases never shadow a real place name, and the first place to claim
+	// an alias keeps it.
+	aliases := make(map[string]string)
+	for key, entries := range places {
+		for _, alias := range allAliases(entries) {
+			aliasKey := NormalizePlace(alias)
+			if aliasKey == "" || aliasKey == key {
+				continue
+			}
+			if _, isPlace := places[aliasKey]; isPlace {
+				continue
+			}
+			if _, taken := aliases[aliasKey]; !taken {
+				aliases[aliasKey] = key
+			}
+		}
+	}
+
+	byFirst := make(map[rune][]string)
+	byLast := make(map[rune][]string)
+	for key := range places {
+		if key == "" {
+			continue
+		}
+		first, last := firstRune(key), lastRune(key)
+		byFirst[first] = append(byFirst[first], key)
+		byLast[last] = append(byLast[last], key)
+	}
+	for _, keys := range byFirst {
+		sort.Strings(keys)
+	}
+	for _, keys := range byLast {
+		sort.Strings(keys)
+	}
+	aliasesByFirst := make(map[rune][]string)
+	for alias := range aliases {
+		first := firstRune(alias)
+		aliasesByFirst[first] = append(aliasesByFirst[first], alias)
+	}
+	for _, keys := range aliasesByFirst {
+		sort.Strings(keys)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.places = places
+	d.aliases = aliases
+	d.byFirst = byFirst
+	d.byLast = byLast
+	d.aliasesByFirst = aliasesByFirst
+	return nil
+}
+
+// fillContinents sets Continent on every entry that lacks one: a country's
+// continent is its Admin, and other places take their country's.
+func fillContinents(places map[string][]PlaceInfo) {
+	byCountry := make(map[string]string)
+	for _, entries := range places {
+		for i, e := range entries {
+			if e.Type == "Country" && e.Continent == "" {
+				entries[i].Continent = e.Admin
+			}
+			if e.Type == "Country" && e.CountryCode != "" && entries[i].Continent != "" {
+				byCountry[strings.ToUpper(e.CountryCode)] = entries[i].Continent
+			}
+		}
+	}
+	for _, entries := range places {
+		for i, e := range entries {
+			if e.Continent == "" && e.CountryCode != "" {
+				entries[i].Continent = byCountry[strings.ToUpper(e.CountryCode)]
+			}
+		}
+	}
+}
+
+// AnyMatch reports whether at least one place counts under f.
+func (d *Dictionary) AnyMatch(f PlaceFilter) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for _, entries := range d.places {
+		for _, e := range entries {
+			if f.Matches(e) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Len reports how many distinct place names the dictionary holds.
+func (d *Dictionary) Len() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return len(d.places)
+}
+
+func allAliases(entries []PlaceInfo) []string {
+	var aliases []string
+	for _, e := range entries {
+		aliases = append(aliases, e.Aliases...)
+	}
+	return aliases
+}
+
+// lookup resolves a name or alias to its highest-tier entry. Must be called
+// with d.mu held.
+func (d *Dictionary) lookup(place string) (PlaceInfo, bool) {
+	entries := d.lookupAll(place)
+	if len(entries) == 0 {
+		return PlaceInfo{}, false
+	}
+	return entries[0], true
+}
+
+// lookupAll resolves a name or alias to every entry sharing it. Must be called
+// with d.mu held.
+func (d *Dictionary) lookupAll(place string) []PlaceInfo {
+	key := NormalizePlace(place)
+	if canonical, ok := d.aliases[key]; ok {
+		key = canonical
 	}
+	return d.places[key]
+}
+
+// Lookup returns every place matching the name or alias, highest tier first.
+func (d *Dictionary) Lookup(place string) []PlaceInfo {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	entries := d.lookupAll(place)
+	return append([]PlaceInfo(nil), entries...)
+}
+
+// Resolve returns the entry for place of the given type, or the highest-tier
+// entry when placeType is empty.
+func (d *Dictionary) Resolve(place, placeType string) (PlaceInfo, bool) {
+	return SelectType(d.Lookup(place), placeType)
+}
 
-	return &Dictionary{
-		places: places,
-	}, nil
+// ResolveIn is Resolve restricted to places that count under filter.
+func (d *Dictionary) ResolveIn(place, placeType string, filter PlaceFilter) (PlaceInfo, bool) {
+	return SelectType(filter.Apply(d.Lookup(place)), placeType)
+}
+
+// SelectType picks the entry of placeType (case-insensitive) from entries
+// returned by Lookup, or the first entry when placeType is empty.
+func SelectType(entries []PlaceInfo, placeType string) (PlaceInfo, bool) {
+	if len(entries) == 0 {
+		return PlaceInfo{}, false
+	}
+	if placeType == "" {
+		return entries[0], true
+	}
+	for _, e := range entries {
+		if strings.EqualFold(e.Type, placeType) {
+			return e, true
+		}
+	}
+	return PlaceInfo{}, false
 }
 
 func (d *Dictionary) IsValid(place string) (bool, string, string) {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
-	info, ok := d.places[strings.ToLower(place)]
+	info, ok := d.lookup(place)
 	if ok {
 		return true, info.Type, info.Name
 	}
@@ -60,19 +277,149 @@ func (d *Dictionary) IsValid(place string) (bool, string, string) {
 func (d *Dictionary) GetInfo(place string) PlaceInfo {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
-	return d.places[strings.ToLower(place)]
+	info, _ := d.lookup(place)
+	return info
+}
+
+// GetUnusedPlaceStartingWith returns a random place whose normalized name
+// starts with letter and whose normalized key is not in used.
+func (d *Dictionary) GetUnusedPlaceStartingWith(letter rune, used map[string]bool) PlaceInfo {
+	return d.PickUnusedStartingWith(letter, used, rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())), nil)
+}
+
+// CountStartingWith reports how many places starting with letter are not in
+// used. It costs O(len(used) log n), not O(dictionary size).
+func (d *Dictionary) CountStartingWith(letter rune, used map[string]bool) int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return countUnused(d.byFirst[letter], used)
+}
+
+// CountEndingWith reports how many places ending with letter are not in used.
+func (d *Dictionary) CountEndingWith(letter rune, used map[string]bool) int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return countUnused(d.byLast[letter], used)
+}
+
+// countUnused counts the keys in a sorted bucket that aren't in used. Used
+// keys the bucket doesn't hold, such as places from another pack or one
+// since reloaded away, don't count against it.
+func countUnused(bucket []string, used map[string]bool) int {
+	n := len(bucket)
+	for key, isUsed := range used {
+		if isUsed && inSorted(bucket, key) {
+			n--
+		}
+	}
+	return n
+}
+
+// CountStartingWithIn is CountStartingWith for places that count under
+// filter. A filter means checking every place in the letter's bucket.
+func (d *Dictionary) CountStartingWithIn(letter rune, used map[string]bool, filter PlaceFilter) int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.countStartingWithInLocked(letter, used, filter)
+}
+
+// countStartingWithInLocked is CountStartingWithIn for callers already
+// holding d.mu, such as a weight function run by PickUnusedStartingWith.
+func (d *Dictionary) countStartingWithInLocked(letter rune, used map[string]bool, filter PlaceFilter) int {
+	if filter.IsZero() {
+		return countUnused(d.byFirst[letter], used)
+	}
+	n := 0
+	for _, key := range d.byFirst[letter] {
+		if !used[key] && len(filter.Apply(d.places[key])) > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func inSorted(keys []string, key string) bool {
+	i := sort.SearchStrings(keys, key)
+	return i < len(keys) && keys[i] == key
 }
 
-func (d *Dictionary) GetUnusedPlaceStartingWith(letter byte, used map[string]bool) PlaceInfo {
+// PickUnusedStartingWith selects an unused place starting with letter.
+//
+// With a nil rng the choice is deterministic: the highest weight wins, or the
+// first name alphabetically when weight is nil. With an rng, places are drawn
+// uniformly, or in proportion to weight when one is given, so a seeded rng
+// gives reproducible picks. Places with a weight <= 0 are never chosen. When
+// a name has several entries, each is weighed and the heaviest stands for it.
+// weight is also given the letter the place ends with, taken from the index
+// so it needn't normalize the name again.
+// weight runs with the dictionary's read lock held, so it must not call
+// methods that take it again: a reload waiting for the write lock would
+// block that second read lock and deadlock the pick. Use the *Locked
+// helpers instead.
+func (d *Dictionary) PickUnusedStartingWith(letter rune, used map[string]bool, rng *rand.Rand, weight func(p PlaceInfo, last rune) float64) PlaceInfo {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
-	target := strings.ToLower(string(letter))
-	for lowerName, info := range d.places {
-		if strings.HasPrefix(lowerName, target) {
-			if !used[lowerName] {
-				return info
+	bucket := d.byFirst[letter]
+	if len(bucket) == 0 {
+		return PlaceInfo{}
+	}
+
+	if weight == nil {
+		if rng == nil {
+			for _, key := range bucket {
+				if !used[key] {
+					return d.places[key][0]
+				}
 			}
+			return PlaceInfo{}
 		}
+		// Used words are a small fraction of a bucket, so a few random
+		// probes almost always hit; fall back to a scan from a random
+		// offset when they don't.
+		for range 8 {
+			key := bucket[rng.IntN(len(bucket))]
+			if !used[key] {
+				return d.places[key][0]
+			}
+		}
+		start := rng.IntN(len(bucket))
+		for i := range bucket {
+			key := bucket[(start+i)%len(bucket)]
+			if !used[key] {
+				return d.places[key][0]
+			}
+		}
+		return PlaceInfo{}
 	}
-	return PlaceInfo{}
-}
\ No newline at end of file
+
+	var chosen PlaceInfo
+	best, total := 0.0, 0.0
+	for _, key := range bucket {
+		if used[key] {
+			continue
+		}
+		var info PlaceInfo
+		w := 0.0
+		last := lastRune(key)
+		for _, e := range d.places[key] {
+			if ew := weight(e, last); ew > w {
+				info, w = e, ew
+			}
+		}
+		if w <= 0 {
+			continue
+		}
+		if rng == nil {
+			if w > best {
+				best, chosen = w, info
+			}
+			continue
+		}
+		// Weighted reservoir sampling: one pass, no extra allocation
+		total += w
+		if rng.Float64()*total < w {
+			chosen = info
+		}
+	}
+	return chosen
+}
@@ -0,0 +1,35 @@
+package game
+
+import "testing"
+
+func TestCountStartingWith(t *testing.T) {
+	dict := testDictionary(t,
+		PlaceInfo{Name: "Oslo", Type: "City"},
+		PlaceInfo{Name: "Ottawa", Type: "City"},
+		PlaceInfo{Name: "Osaka", Type: "City"},
+	)
+
+	tests := []struct {
+		name string
+		used []string
+		want int
+	}{
+		{"nothing used", nil, 3},
+		{"one used", []string{"oslo"}, 2},
+		{"all used", []string{"oslo", "ottawa", "osaka"}, 0},
+		// Keys from another pack, or reloaded away, aren't in the bucket
+		{"used keys not in the dictionary", []string{"oslo", "omsk", "odessa", "oran", "orlando"}, 2},
+		{"used keys for other letters", []string{"paris", "lima"}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			used := make(map[string]bool)
+			for _, key := range tt.used {
+				used[key] = true
+			}
+			if got := dict.CountStartingWith('o', used); got != tt.want {
+				t.Errorf("CountStartingWith('o') = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PlaceFilter restricts which places count in a game, e.g. "Countries only"
+// or "Capitals only". Empty fields match everything.
+type PlaceFilter struct {
+	Types        []string `json:"types,omitempty"`      // e.g. ["Country"]
+	Countries    []string `json:"countries,omitempty"`  // ISO 3166-1 alpha-2 codes
+	Continents   []string `json:"continents,omitempty"` // e.g. ["Europe"]
+	CapitalsOnly bool     `json:"capitalsOnly,omitempty"`
+}
+
+// IsZero reports whether the filter lets every place through.
+func (f PlaceFilter) IsZero() bool {
+	return len(f.Types) == 0 && len(f.Countries) == 0 && len(f.Continents) == 0 && !f.CapitalsOnly
+}
+
+// Validate reports a filter naming a place type that doesn't exist.
+func (f PlaceFilter) Validate() error {
+	for _, t := range f.Types {
+		known := false
+		for name := range placeTypeRank {
+			known = known || strings.EqualFold(t, name)
+		}
+		if !known {
+			return fmt.Errorf("Unknown place type '%s' (use City, State, Country or Continent)", t)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether p counts under the filter.
+func (f PlaceFilter) Matches(p PlaceInfo) bool {
+	if len(f.Types) > 0 && !containsFold(f.Types, p.Type) {
+		return false
+	}
+	if len(f.Countries) > 0 && !containsFold(f.Countries, p.CountryCode) {
+		return false
+	}
+	if len(f.Continents) > 0 && !containsFold(f.Continents, p.Continent) {
+		return false
+	}
+	if f.CapitalsOnly && !p.Capital {
+		return false
+	}
+	return true
+}
+
+// Apply returns the entries that count under the filter, keeping their order.
+func (f PlaceFilter) Apply(entries []PlaceInfo) []PlaceInfo {
+	if f.IsZero() {
+		return entries
+	}
+	var kept []PlaceInfo
+	for _, e := range entries {
+		if f.Matches(e) {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+// String describes the filter for players, e.g. "City, in FR, capitals".
+func (f PlaceFilter) String() string {
+	var parts []string
+	if len(f.Types) > 0 {
+		parts = append(parts, strings.Join(f.Types, "/"))
+	}
+	if len(f.Countries) > 0 {
+		parts = append(parts, "in "+strings.Join(f.Countries, ", "))
+	}
+	if len(f.Continents) > 0 {
+		parts = append(parts, "in "+strings.Join(f.Continents, ", "))
+	}
+	if f.CapitalsOnly {
+		parts = append(parts, "capitals")
+	}
+	return strings.Join(parts, ", ")
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package game
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPlaceFilterMatches(t *testing.T) {
+	dict := testDictionary(t,
+		PlaceInfo{Name: "France", Type: "Country", CountryCode: "FR", Admin: "Europe"},
+		PlaceInfo{Name: "Paris", Type: "City", CountryCode: "FR", Capital: true},
+		PlaceInfo{Name: "Lyon", Type: "City", CountryCode: "fr"},
+		PlaceInfo{Name: "Japan", Type: "Country", CountryCode: "JP", Admin: "Asia"},
+		PlaceInfo{Name: "Tokyo", Type: "City", CountryCode: "JP", Capital: true},
+	)
+
+	tests := []struct {
+		name   string
+		filter PlaceFilter
+		place  string
+		want   bool
+	}{
+		{"no filter", PlaceFilter{}, "Lyon", true},
+		{"type", PlaceFilter{Types: []string{"country"}}, "France", true},
+		{"wrong type", PlaceFilter{Types: []string{"Country"}}, "Paris", false},
+		{"country code", PlaceFilter{Countries: []string{"fr"}}, "Lyon", true},
+		{"other country", PlaceFilter{Countries: []string{"FR"}}, "Tokyo", false},
+		{"continent from the country", PlaceFilter{Continents: []string{"europe"}}, "Lyon", true},
+		{"continent of a country", PlaceFilter{Continents: []string{"Asia"}}, "Japan", true},
+		{"other continent", PlaceFilter{Continents: []string{"Asia"}}, "Paris", false},
+		{"capitals", PlaceFilter{CapitalsOnly: true}, "Paris", true},
+		{"not a capital", PlaceFilter{CapitalsOnly: true}, "Lyon", false},
+		{"all at once", PlaceFilter{Types: []string{"City"}, Continents: []string{"Asia"}, CapitalsOnly: true}, "Tokyo", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			place, ok := dict.Resolve(tt.place, "")
+			if !ok {
+				t.Fatalf("%s isn't in the dictionary", tt.place)
+			}
+			if got := tt.filter.Matches(place); got != tt.want {
+				t.Errorf("%+v.Matches(%s) = %v, want %v", tt.filter, tt.place, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigureFilter(t *testing.T) {
+	dict := testDictionary(t,
+		PlaceInfo{Name: "France", Type: "Country", CountryCode: "FR", Admin: "Europe"},
+		PlaceInfo{Name: "Paris", Type: "City", CountryCode: "FR", Capital: true},
+	)
+
+	tests := []struct {
+		name    string
+		payload string
+		wantErr bool
+	}{
+		{"countries only", `{"filter": {"types": ["Country"]}}`, false},
+		{"capitals in Europe", `{"filter": {"continents": ["Europe"], "capitalsOnly": true}}`, false},
+		{"clearing the filter", `{"filter": {}}`, false},
+		{"unknown type", `{"filter": {"types": ["Village"]}}`, true},
+		{"nothing left to play", `{"filter": {"continents": ["Asia"]}}`, true},
+		{"no capitals of that type", `{"filter": {"types": ["Country"], "capitalsOnly": true}}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRoom("test", dict, nil)
+			r.Filter = PlaceFilter{Types: []string{"City"}}
+			var cfg roomConfig
+			if err := json.Unmarshal([]byte(tt.payload), &cfg); err != nil {
+				t.Fatal(err)
+			}
+
+			err := r.configure(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("configure(%s) = %v, want error %v", tt.payload, err, tt.wantErr)
+			}
+			if err != nil && (len(r.Filter.Types) != 1 || r.Filter.Types[0] != "City") {
+				t.Errorf("rejected filter changed the room's filter to %+v", r.Filter)
+			}
+			if err == nil && r.Filter.String() != cfg.Filter.String() {
+				t.Errorf("filter = %q, want %q", r.Filter, cfg.Filter)
+			}
+		})
+	}
+}
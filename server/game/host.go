@@ -0,0 +1,189 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// hostActions may only be sent by the room's host.
+var hostActions = map[string]bool{
+	"START_GAME":      true,
+	"ADD_BOT":         true,
+	"KICK_PLAYER":     true,
+	"REMOVE_BOT":      true,
+	"TRANSFER_HOST":   true,
+	"UPDATE_SETTINGS": true,
+	"RESET_GAME":      true,
+}
+
+var gameModes = map[string]bool{
+	"CLASSIC":      true,
+	"POINT_RUSH":   true,
+	"SUDDEN_DEATH": true,
+}
+
+// roomConfig is the lobby configuration sent with UPDATE_SETTINGS or
+// START_GAME. Omitted fields keep their current value.
+type roomConfig struct {
+	Mode     string         `json:"mode"` // CLASSIC, POINT_RUSH, SUDDEN_DEATH
+	Settings map[string]int `json:"settings"`
+	Pack     string         `json:"pack"`
+	Filter   *PlaceFilter   `json:"filter"`
+	LateJoin string         `json:"lateJoin"` // REJECT, SPECTATE or QUEUE
+}
+
+// configure validates cfg and applies it to the room. Nothing changes if any
+// field is invalid. Must be called with r.mu held.
+func (r *Room) configure(cfg roomConfig) error {
+	mode := strings.ToUpper(cfg.Mode)
+	if mode != "" && !gameModes[mode] {
+		return fmt.Errorf("Unknown game mode '%s'", cfg.Mode)
+	}
+	var dict *Dictionary
+	if cfg.Pack != "" && r.Packs != nil {
+		d, ok := r.Packs.Get(cfg.Pack)
+		if !ok {
+			return fmt.Errorf("Unknown data pack '%s'", cfg.Pack)
+		}
+		dict = d
+	}
+	// A filter that leaves nothing to play would reject every move, whether
+	// it's new or the pack under it changed
+	filter, filterDict := r.Filter, r.Dict
+	if cfg.Filter != nil {
+		filter = *cfg.Filter
+	}
+	if dict != nil {
+		filterDict = dict
+	}
+	if (cfg.Filter != nil || dict != nil) && !filter.IsZero() {
+		if err := filter.Validate(); err != nil {
+			return err
+		}
+		if !filterDict.AnyMatch(filter) {
+			return fmt.Errorf("No places in this data pack count under that filter (%s)", filter)
+		}
+	}
+
+	if mode != "" {
+		r.Mode = mode
+	}
+	if cfg.Settings != nil {
+		r.Settings = cfg.Settings
+	}
+	if dict != nil {
+		r.Dict = dict
+		r.Pack = cfg.Pack
+	}
+	if cfg.Filter != nil {
+		r.Filter = *cfg.Filter
+	}
+	if cfg.LateJoin != "" {
+		r.LateJoin = ParseLateJoinPolicy(cfg.LateJoin)
+	}
+	return nil
+}
+
+// claimHost makes p the host if the room has none. Must be called with r.mu
+// held.
+func (r *Room) claimHost(p *Player) {
+	if r.HostID == "" && p.Type == PlayerHuman {
+		r.HostID = p.ID
+	}
+}
+
+// reassignHost hands the room to the longest-seated connected human, falling
+// back to a spectator, when the host leaves. Must be called with r.mu held.
+func (r *Room) reassignHost() {
+	r.HostID = ""
+	for _, id := range r.TurnOrder {
+		if p := r.Players[id]; p.Type == PlayerHuman && p.Connected {
+			r.HostID = id
+			break
+		}
+	}
+	if r.HostID == "" {
+		for id, p := range r.Spectators {
+			if p.Connected {
+				r.HostID = id
+				break
+			}
+		}
+	}
+	if r.HostID != "" {
+		log.Printf("[Room %s] Host is now %s", r.ID, r.HostID)
+	}
+}
+
+// handleHostAction runs an administrative action the host has sent. Must be
+// called with r.mu held.
+func (r *Room) handleHostAction(action *ActionMessage) {
+	var target struct {
+		PlayerID string `json:"playerId"`
+	}
+
+	switch action.Type {
+	case "KICK_PLAYER":
+		_ = json.Unmarshal(action.Payload, &target)
+		p, ok := r.member(target.PlayerID)
+		if !ok || p.Type != PlayerHuman {
+			r.sendError(action.PlayerID, "No such player to kick")
+			return
+		}
+		if p.ID == action.PlayerID {
+			r.sendError(action.PlayerID, "You can't kick yourself")
+			return
+		}
+		log.Printf("[Room %s] %s was kicked", r.ID, p.Name)
+		sendTo(p, "KICKED", map[string]string{"reason": "You were removed by the host"})
+		r.removePlayer(p) // The write pump delivers KICKED, then hangs up
+
+	case "REMOVE_BOT":
+		_ = json.Unmarshal(action.Payload, &target)
+		p, ok := r.Players[target.PlayerID]
+		if !ok || p.Type != PlayerBot {
+			r.sendError(action.PlayerID, "No such bot")
+			return
+		}
+		r.leaveGame(p)
+
+	case "TRANSFER_HOST":
+		_ = json.Unmarshal(action.Payload, &target)
+		p, ok := r.member(target.PlayerID)
+		if !ok || p.Type != PlayerHuman {
+			r.sendError(action.PlayerID, "Host can only be handed to a human in this room")
+			return
+		}
+		r.HostID = p.ID
+
+	case "UPDATE_SETTINGS":
+		if r.State == StatePlaying {
+			r.sendError(action.PlayerID, "Settings can only be changed between games")
+			return
+		}
+		var cfg roomConfig
+		if err := json.Unmarshal(action.Payload, &cfg); err != nil {
+			r.sendError(action.PlayerID, "Invalid settings")
+			return
+		}
+		if err := r.configure(cfg); err != nil {
+			r.sendError(action.PlayerID, err.Error())
+			return
+		}
+
+	case "RESET_GAME":
+		r.resetGame()
+	}
+
+	r.broadcastStateInternal()
+}
+
+// resetGame abandons any game in progress and returns the room to the lobby
+// with everyone's score and lives restored. Must be called with r.mu held.
+func (r *Room) resetGame() {
+	r.seatLateJoiners()
+	r.apply(GameReset{})
+	log.Printf("[Room %s] Game reset by host", r.ID)
+}
@@ -0,0 +1,107 @@
+package game
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func action(t *testing.T, actionType, playerID string, payload interface{}) *ActionMessage {
+	t.Helper()
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &ActionMessage{Type: actionType, PlayerID: playerID, Payload: data}
+}
+
+func TestHostOnlyActions(t *testing.T) {
+	tests := []struct {
+		name    string
+		action  string
+		payload interface{}
+		changed func(r *Room) bool
+	}{
+		{"kick", "KICK_PLAYER", map[string]string{"playerId": "cat"}, func(r *Room) bool { _, ok := r.Players["cat"]; return !ok }},
+		{"transfer host", "TRANSFER_HOST", map[string]string{"playerId": "bob"}, func(r *Room) bool { return r.HostID != "ann" }},
+		{"settings", "UPDATE_SETTINGS", map[string]string{"mode": "SUDDEN_DEATH"}, func(r *Room) bool { return r.Mode != "CLASSIC" }},
+		{"add bot", "ADD_BOT", nil, func(r *Room) bool { return len(r.Players) != 3 }},
+		{"start", "START_GAME", nil, func(r *Room) bool { return r.State != StateWaiting }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, players := testRoom(t, nil, "ann", "bob", "cat")
+			bob := players[1]
+
+			r.handleAction(action(t, tt.action, bob.ID, tt.payload))
+			if tt.changed(r) {
+				t.Errorf("%s from a non-host took effect", tt.action)
+			}
+			if msg, ok := received(bob, "ERROR"); !ok || msg["message"] != "Only the host can do that" {
+				t.Errorf("non-host wasn't told off: %v", msg)
+			}
+
+			r.handleAction(action(t, tt.action, "ann", tt.payload))
+			if !tt.changed(r) {
+				t.Errorf("%s from the host didn't take effect", tt.action)
+			}
+		})
+	}
+}
+
+func TestKickHandsOnHost(t *testing.T) {
+	r, players := testRoom(t, nil, "ann", "bob")
+	r.handleAction(action(t, "TRANSFER_HOST", "ann", map[string]string{"playerId": "bob"}))
+	r.handleAction(action(t, "KICK_PLAYER", "bob", map[string]string{"playerId": "ann"}))
+
+	if _, ok := received(players[0], "KICKED"); !ok {
+		t.Errorf("kicked player wasn't told")
+	}
+	if _, ok := r.Players["ann"]; ok {
+		t.Errorf("kicked player is still seated")
+	}
+	r.handleAction(action(t, "KICK_PLAYER", "bob", map[string]string{"playerId": "bob"}))
+	if _, ok := r.Players["bob"]; !ok {
+		t.Errorf("host kicked themselves")
+	}
+}
+
+func TestResetGame(t *testing.T) {
+	r, players := startedRoom(t, "ann", "bob")
+	r.LateJoin = LateJoinQueue
+	late := testLateJoiner(t, r, "cat")
+
+	r.mu.Lock()
+	r.apply(TurnTimedOut{PlayerID: "ann"})
+	r.mu.Unlock()
+	if players[0].Lives != 2 {
+		t.Fatalf("ann has %d lives, want 2 after timing out", players[0].Lives)
+	}
+
+	r.handleAction(action(t, "RESET_GAME", "ann", nil))
+	if r.State != StateWaiting {
+		t.Errorf("state = %s after reset, want %s", r.State, StateWaiting)
+	}
+	for _, p := range []*Player{players[0], players[1], late} {
+		if p.Lives != 3 || p.Score != 0 {
+			t.Errorf("%s has %d lives and %d points after reset", p.Name, p.Lives, p.Score)
+		}
+	}
+	if _, seated := r.Players["cat"]; !seated || len(r.TurnOrder) != 3 {
+		t.Errorf("queued player wasn't seated by the reset: order %v", r.TurnOrder)
+	}
+	if len(r.History) != 0 || len(r.UsedWords) != 0 || r.replay != nil {
+		t.Errorf("reset kept the old game's history")
+	}
+}
+
+// testLateJoiner admits a new human to r while its game is in progress.
+func testLateJoiner(t *testing.T, r *Room, name string) *Player {
+	t.Helper()
+	p := NewPlayer(name, name, PlayerHuman, nil)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.admit(p) {
+		t.Fatalf("%s wasn't admitted", name)
+	}
+	return p
+}
@@ -0,0 +1,114 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Late-join policies decide what happens to someone who connects as a player
+// while a game is already running.
+const (
+	LateJoinReject   = "REJECT"   // Turn them away with JOIN_REJECTED
+	LateJoinSpectate = "SPECTATE" // Let them watch this game
+	LateJoinQueue    = "QUEUE"    // Let them watch, then seat them next game
+)
+
+// DefaultLateJoin is the policy used until the host picks another.
+const DefaultLateJoin = LateJoinQueue
+
+// ParseLateJoinPolicy maps a client-supplied policy to a known one, falling
+// back to DefaultLateJoin.
+func ParseLateJoinPolicy(s string) string {
+	switch p := strings.ToUpper(strings.TrimSpace(s)); p {
+	case LateJoinReject, LateJoinSpectate, LateJoinQueue:
+		return p
+	default:
+		return DefaultLateJoin
+	}
+}
+
+// admit adds a newly connected human to the room, applying the late-join
+// policy if a game is in progress. It reports false if they were turned away.
+// Must be called with r.mu held.
+func (r *Room) admit(player *Player) (admitted bool) {
+	defer func() {
+		if admitted {
+			r.record(ReplayEvent{Type: "PLAYER_JOINED", PlayerID: player.ID, PlayerName: player.Name, Spectator: player.Spectator})
+		}
+	}()
+	if player.Spectator {
+		r.Spectators[player.ID] = player
+		r.claimHost(player)
+		return true
+	}
+	if r.State != StatePlaying {
+		if r.seatsFull() {
+			r.reject(player, errRoomFull.Reason)
+			return false
+		}
+		r.Players[player.ID] = player
+		r.TurnOrder = append(r.TurnOrder, player.ID)
+		r.claimHost(player)
+		return true
+	}
+
+	switch r.LateJoin {
+	case LateJoinReject:
+		r.reject(player, errGameInProgress.Reason)
+		return false
+	case LateJoinSpectate:
+		player.Spectator = true
+		r.Spectators[player.ID] = player
+		r.claimHost(player)
+	default:
+		if r.seatsFull() {
+			r.reject(player, errRoomFull.Reason)
+			return false
+		}
+		player.Spectator = true
+		r.Spectators[player.ID] = player
+		r.lateJoiners = append(r.lateJoiners, player.ID)
+		r.claimHost(player)
+		sendTo(player, "QUEUED", map[string]interface{}{
+			"position": len(r.lateJoiners),
+			"message":  fmt.Sprintf("You'll join the next game (#%d in line). Watching for now.", len(r.lateJoiners)),
+		})
+	}
+	return true
+}
+
+// reject turns away a connection that was never added to the room. Must be
+// called with r.mu held.
+func (r *Room) reject(player *Player, reason string) {
+	log.Printf("[Room %s] Rejected %s: %s", r.ID, player.Name, reason)
+	sendTo(player, "JOIN_REJECTED", map[string]string{"reason": reason})
+	close(player.Send) // The write pump delivers the message, then hangs up
+}
+
+// seatLateJoiners moves everyone queued during the last game into the player
+// list, in the order they arrived. Must be called with r.mu held.
+func (r *Room) seatLateJoiners() {
+	for _, id := range r.lateJoiners {
+		p, ok := r.Spectators[id]
+		if !ok {
+			continue
+		}
+		delete(r.Spectators, id)
+		p.Spectator = false
+		r.Players[id] = p
+		r.TurnOrder = append(r.TurnOrder, id)
+	}
+	r.lateJoiners = nil
+}
+
+// dequeue drops id from the late-join queue, if present. Must be called with
+// r.mu held.
+func (r *Room) dequeue(id string) {
+	for i, queued := range r.lateJoiners {
+		if queued == id {
+			r.lateJoiners = append(r.lateJoiners[:i], r.lateJoiners[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package game
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// received drains everything queued for p and returns the payload of the
+// last message of msgType, if any.
+func received(p *Player, msgType string) (map[string]interface{}, bool) {
+	var payload map[string]interface{}
+	found := false
+	for {
+		select {
+		case data, ok := <-p.Send:
+			if !ok {
+				return payload, found
+			}
+			var msg struct {
+				Type    string                 `json:"type"`
+				Payload map[string]interface{} `json:"payload"`
+			}
+			if json.Unmarshal(data, &msg) == nil && msg.Type == msgType {
+				payload, found = msg.Payload, true
+			}
+		default:
+			return payload, found
+		}
+	}
+}
+
+// startedRoom is testRoom with a game already in progress.
+func startedRoom(t *testing.T, names ...string) (*Room, []*Player) {
+	t.Helper()
+	r, players := testRoom(t, nil, names...)
+	r.startGame()
+	if r.State != StatePlaying {
+		t.Fatalf("game didn't start")
+	}
+	return r, players
+}
+
+func TestLateJoinPolicies(t *testing.T) {
+	tests := []struct {
+		policy    string
+		admitted  bool
+		spectator bool
+		queued    bool
+		msgType   string
+	}{
+		{LateJoinReject, false, false, false, "JOIN_REJECTED"},
+		{LateJoinSpectate, true, true, false, ""},
+		{LateJoinQueue, true, true, true, "QUEUED"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			r, _ := startedRoom(t, "ann", "bob")
+			r.LateJoin = tt.policy
+			late := NewPlayer("cat", "cat", PlayerHuman, &websocket.Conn{})
+
+			r.mu.Lock()
+			admitted := r.admit(late)
+			r.mu.Unlock()
+			if admitted != tt.admitted {
+				t.Fatalf("admit() = %v, want %v", admitted, tt.admitted)
+			}
+			if _, seated := r.Players["cat"]; seated {
+				t.Errorf("late joiner took a seat mid-game")
+			}
+			if _, watching := r.Spectators["cat"]; watching != tt.spectator {
+				t.Errorf("spectating = %v, want %v", watching, tt.spectator)
+			}
+			if queued := len(r.lateJoiners) == 1; queued != tt.queued {
+				t.Errorf("queued = %v, want %v", queued, tt.queued)
+			}
+			if tt.msgType != "" {
+				if _, ok := received(late, tt.msgType); !ok {
+					t.Errorf("late joiner wasn't sent %s", tt.msgType)
+				}
+			}
+
+			// Only the queue seats them in the next game
+			r.mu.Lock()
+			r.apply(GameReset{})
+			r.mu.Unlock()
+			r.startGame()
+			if _, seated := r.Players["cat"]; seated != tt.queued {
+				t.Errorf("seated next game = %v, want %v", seated, tt.queued)
+			}
+		})
+	}
+}
+
+func TestLateJoinQueueRespectsCap(t *testing.T) {
+	r, _ := startedRoom(t, "ann", "bob")
+	r.Options.MaxPlayers = 3
+
+	first := NewPlayer("cat", "cat", PlayerHuman, &websocket.Conn{})
+	second := NewPlayer("dan", "dan", PlayerHuman, &websocket.Conn{})
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.admit(first) {
+		t.Fatalf("first late joiner was turned away with a seat free")
+	}
+	if r.admit(second) {
+		t.Errorf("second late joiner was queued for a full room")
+	}
+	if _, ok := received(second, "JOIN_REJECTED"); !ok {
+		t.Errorf("second late joiner wasn't sent JOIN_REJECTED")
+	}
+}
+
+func TestAddBotMidGame(t *testing.T) {
+	r, players := startedRoom(t, "ann", "bob")
+	order := append([]string(nil), r.TurnOrder...)
+
+	r.handleAction(&ActionMessage{Type: "ADD_BOT", PlayerID: players[0].ID})
+	if len(r.Players) != 2 || len(r.TurnOrder) != len(order) {
+		t.Errorf("a bot joined mid-game: order %v", r.TurnOrder)
+	}
+	if len(r.replay.Players) != 2 {
+		t.Errorf("replay has %d players, want 2", len(r.replay.Players))
+	}
+	if _, ok := received(players[0], "ERROR"); !ok {
+		t.Errorf("host wasn't told why the bot wasn't added")
+	}
+
+	// Between games it's fine
+	r.mu.Lock()
+	r.apply(GameReset{})
+	r.mu.Unlock()
+	r.handleAction(&ActionMessage{Type: "ADD_BOT", PlayerID: players[0].ID})
+	if len(r.Players) != 3 {
+		t.Errorf("bot wasn't added in the lobby")
+	}
+}
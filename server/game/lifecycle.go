@@ -0,0 +1,117 @@
+package game
+
+import (
+	"log"
+	"time"
+)
+
+// DefaultRoomIdleTTL is how long a room may go without a connected human
+// before the reaper closes it.
+const DefaultRoomIdleTTL = 10 * time.Minute
+
+// deliver sends v on one of r's channels, giving up if the room shuts down
+// first. It reports whether v was delivered. Every goroutine other than Run
+// talks to the room this way, so none is left blocked on a dead room.
+func deliver[T any](r *Room, ch chan T, v T) bool {
+	select {
+	case ch <- v:
+		return true
+	case <-r.done:
+		return false
+	}
+}
+
+// Close shuts the room down: Run stops, pending timers and bot turns are
+// abandoned, and every connected human is sent ROOM_CLOSED with reason and
+// hung up on. It is safe to call more than once and from any goroutine.
+func (r *Room) Close(reason string) {
+	r.closeOnce.Do(func() {
+		r.closeReason = reason
+		close(r.done)
+	})
+}
+
+// Stop closes the room and waits for Run to return, after which nothing
+// about the room changes.
+func (r *Room) Stop(reason string) {
+	r.Close(reason)
+	<-r.stopped
+}
+
+func (r *Room) closed() bool {
+	select {
+	case <-r.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// shutdown releases everything the room holds. Run calls it on its way out.
+// The turn and game deadlines are left set, so a snapshot taken afterwards
+// still knows how much time was left.
+func (r *Room) shutdown() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, timer := range []*time.Timer{r.turnTimer, r.gameTimer} {
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+	r.turnTimer, r.gameTimer = nil, nil
+	r.cancelBotTurn()
+	for _, group := range []map[string]*Player{r.Players, r.Spectators} {
+		for _, p := range group {
+			if p.Type != PlayerHuman || !p.Connected {
+				continue
+			}
+			sendTo(p, "ROOM_CLOSED", map[string]string{"reason": r.closeReason})
+			close(p.Send) // The write pump delivers ROOM_CLOSED, then hangs up
+			p.Connected = false
+		}
+	}
+	log.Printf("[Room %s] Closed: %s", r.ID, r.closeReason)
+}
+
+// idleFor reports how long the room has had no connected human, as of now.
+func (r *Room) idleFor(now time.Time) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, group := range []map[string]*Player{r.Players, r.Spectators} {
+		for _, p := range group {
+			if p.Type == PlayerHuman && p.Connected {
+				r.idleSince = time.Time{}
+				return 0
+			}
+		}
+	}
+	if r.idleSince.IsZero() {
+		r.idleSince = now
+	}
+	return now.Sub(r.idleSince)
+}
+
+// StartReaper closes and forgets rooms that have had no connected human for
+// ttl, checking several times per ttl.
+func (m *Manager) StartReaper(ttl time.Duration) {
+	interval := min(max(ttl/4, time.Second), time.Minute)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			m.reapIdleRooms(ttl, now)
+		}
+	}()
+}
+
+func (m *Manager) reapIdleRooms(ttl time.Duration, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, room := range m.rooms {
+		if room.idleFor(now) >= ttl {
+			delete(m.rooms, id)
+			room.Close("Room closed after being idle")
+		}
+	}
+}
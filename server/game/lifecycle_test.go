@@ -0,0 +1,46 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReapIdleRooms(t *testing.T) {
+	const ttl = 10 * time.Minute
+	m := NewManager(nil, nil)
+	empty := NewRoom("EMPTY", nil, nil)
+	t.Cleanup(func() { empty.Close("test over") })
+	m.rooms["EMPTY"] = empty
+	busy, _ := testRoom(t, nil, "ann", "bob")
+	m.rooms["BUSY"] = busy
+	dropped, players := testRoom(t, nil, "cat")
+	m.rooms["DROP"] = dropped
+
+	start := time.Now()
+	dropped.handleDisconnect(connEvent{player: players[0], conn: players[0].Conn})
+
+	tests := []struct {
+		name  string
+		now   time.Time
+		alive []string // Rooms still open and listed afterwards
+	}{
+		// The first sweep only notices the rooms are idle
+		{"first sweep", start, []string{"BUSY", "DROP", "EMPTY"}},
+		{"just short of the ttl", start.Add(ttl - time.Second), []string{"BUSY", "DROP", "EMPTY"}},
+		{"idle for the ttl", start.Add(ttl), []string{"BUSY"}},
+		{"long after", start.Add(10 * ttl), []string{"BUSY"}},
+	}
+	for _, tt := range tests {
+		m.reapIdleRooms(ttl, tt.now)
+		for id, room := range map[string]*Room{"EMPTY": empty, "BUSY": busy, "DROP": dropped} {
+			want := false
+			for _, alive := range tt.alive {
+				want = want || alive == id
+			}
+			_, listed := m.rooms[id]
+			if listed != want || room.closed() == want {
+				t.Errorf("%s: room %s listed %v, closed %v; want it alive %v", tt.name, id, listed, room.closed(), want)
+			}
+		}
+	}
+}
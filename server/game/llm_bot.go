@@ -0,0 +1,214 @@
+package game
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultLLMTimeout bounds how long an LLM bot waits for an answer before
+// falling back to the dictionary.
+const DefaultLLMTimeout = 5 * time.Second
+
+// llmFallbackTime is kept back from the caller's deadline so the fallback
+// strategy still has time to answer when the model is slow.
+const llmFallbackTime = 500 * time.Millisecond
+
+// maxPromptUsedWords caps how many already-played places go into a prompt.
+const maxPromptUsedWords = 50
+
+// LLMProvider sends a prompt to a language model and returns its reply.
+type LLMProvider interface {
+	Complete(ctx context.Context, prompt string) (string, error)
+}
+
+// OpenAIProvider talks to any OpenAI-compatible chat completions endpoint.
+type OpenAIProvider struct {
+	BaseURL string // e.g. https://api.openai.com/v1
+	APIKey  string
+	Model   string
+	Client  *http.Client
+}
+
+func (p *OpenAIProvider) Complete(ctx context.Context, prompt string) (string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"model": p.Model,
+		"messages": []map[string]string{
+			{"role": "user", "content": prompt},
+		},
+		"temperature": 0.7,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	url := strings.TrimRight(p.BaseURL, "/") + "/chat/completions"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.APIKey)
+	}
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("llm provider returned %s", resp.Status)
+	}
+
+	var out struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", err
+	}
+	if len(out.Choices) == 0 {
+		return "", fmt.Errorf("llm provider returned no choices")
+	}
+	return out.Choices[0].Message.Content, nil
+}
+
+// LLMBot asks a language model for an answer. Whatever the model says is
+// checked against the dictionary and the game's rules; on a bad answer, an
+// error or a timeout it plays the Fallback strategy instead.
+type LLMBot struct {
+	Provider LLMProvider
+	Fallback BotStrategy
+	Timeout  time.Duration
+}
+
+func (b *LLMBot) GetMove(ctx context.Context, turn BotTurn) PlaceInfo {
+	timeout := b.Timeout
+	if timeout <= 0 {
+		timeout = DefaultLLMTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Add(-llmFallbackTime).Before(deadline) {
+		deadline = d.Add(-llmFallbackTime)
+	}
+	llmCtx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	reply, err := b.Provider.Complete(llmCtx, buildPrompt(turn))
+	if err != nil {
+		log.Printf("[Bot] LLM error, falling back: %v", err)
+	} else if move, ok := pickValidAnswer(reply, turn); ok {
+		log.Printf("[Bot] LLM answered: %s (%s)", move.Name, move.Type)
+		return move
+	} else {
+		log.Printf("[Bot] LLM gave no playable answer, falling back: %q", reply)
+	}
+
+	if ctx.Err() != nil {
+		return PlaceInfo{}
+	}
+	return b.Fallback.GetMove(ctx, turn)
+}
+
+func buildPrompt(turn BotTurn) string {
+	var b strings.Builder
+	letter := strings.ToUpper(string(turn.TargetLetter()))
+	fmt.Fprintf(&b, "We are playing a geography word chain game. Name a real place (city, state, country or continent) starting with the letter %s.", letter)
+	if !turn.Filter.IsZero() {
+		fmt.Fprintf(&b, " Only these count: %s.", turn.Filter)
+	}
+
+	// Only used places starting with the target letter could be played by
+	// mistake, so those are the ones kept when the list is cut short
+	target := turn.TargetLetter()
+	used := make([]string, 0, len(turn.UsedWords))
+	for key, isUsed := range turn.UsedWords {
+		if isUsed {
+			used = append(used, key)
+		}
+	}
+	if len(used) > 0 {
+		sort.Slice(used, func(i, j int) bool {
+			iHit, jHit := FirstLetter(used[i]) == target, FirstLetter(used[j]) == target
+			if iHit != jHit {
+				return iHit
+			}
+			return used[i] < used[j]
+		})
+		if len(used) > maxPromptUsedWords {
+			used = used[:maxPromptUsedWords]
+		}
+		fmt.Fprintf(&b, " Do not use any of these: %s.", strings.Join(used, ", "))
+	}
+
+	b.WriteString(" Reply with up to 5 candidate place names, one per line, and nothing else.")
+	return b.String()
+}
+
+// pickValidAnswer returns the first line of reply that is a legal move this
+// turn: a known place, starting with the right letter, unused, and allowed by
+// the game's filter.
+func pickValidAnswer(reply string, turn BotTurn) (PlaceInfo, bool) {
+	for _, line := range strings.Split(reply, "\n") {
+		candidate := strings.TrimSpace(strings.TrimLeft(line, "-*0123456789.) \t"))
+		if candidate == "" {
+			continue
+		}
+		place, ok := turn.Dict.ResolveIn(candidate, "", turn.Filter)
+		if !ok {
+			continue
+		}
+		if FirstLetter(place.Name) != turn.TargetLetter() || turn.UsedWords[NormalizePlace(place.Name)] {
+			continue
+		}
+		return place, true
+	}
+	return PlaceInfo{}, false
+}
+
+// NewBotStrategyFromEnv returns an LLM-backed strategy when LLM_BASE_URL is
+// set (with optional LLM_API_KEY, LLM_MODEL and LLM_TIMEOUT_MS), and the
+// dictionary strategy otherwise.
+func NewBotStrategyFromEnv() BotStrategy {
+	baseURL := os.Getenv("LLM_BASE_URL")
+	if baseURL == "" {
+		return NewBot()
+	}
+
+	model := os.Getenv("LLM_MODEL")
+	if model == "" {
+		model = "gpt-4o-mini"
+	}
+	timeout := DefaultLLMTimeout
+	if ms, err := strconv.Atoi(os.Getenv("LLM_TIMEOUT_MS")); err == nil && ms > 0 {
+		timeout = time.Duration(ms) * time.Millisecond
+	}
+
+	return &LLMBot{
+		Provider: &OpenAIProvider{
+			BaseURL: baseURL,
+			APIKey:  os.Getenv("LLM_API_KEY"),
+			Model:   model,
+			Client:  &http.Client{Timeout: timeout},
+		},
+		Fallback: NewBot(),
+		Timeout:  timeout,
+	}
+}
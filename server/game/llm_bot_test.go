@@ -0,0 +1,147 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testDictionary writes places to a temporary file and loads it.
+func testDictionary(t *testing.T, places ...PlaceInfo) *Dictionary {
+	t.Helper()
+	data, err := json.Marshal(places)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "places.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	d, err := NewDictionary(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// fakeLLM serves an OpenAI-style chat completions endpoint that answers
+// every prompt with reply, after delay.
+func fakeLLM(t *testing.T, reply string, delay time.Duration) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"content": reply}},
+			},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// fixedStrategy always plays the same place.
+type fixedStrategy PlaceInfo
+
+func (s fixedStrategy) GetMove(ctx context.Context, turn BotTurn) PlaceInfo {
+	return PlaceInfo(s)
+}
+
+func TestLLMBotGetMove(t *testing.T) {
+	dict := testDictionary(t,
+		PlaceInfo{Name: "Toronto", Type: "City"},
+		PlaceInfo{Name: "Oslo", Type: "City"},
+		PlaceInfo{Name: "Ottawa", Type: "City"},
+		PlaceInfo{Name: "Lima", Type: "City"},
+	)
+	fallback := PlaceInfo{Name: "Fallback", Type: "City"}
+
+	tests := []struct {
+		name  string
+		reply string
+		delay time.Duration
+		used  []string
+		want  string
+	}{
+		{"valid answer", "1. Oslo\n2. Ottawa", 0, nil, "Oslo"},
+		{"skips used and unknown places", "Xyzzyville\nOslo\nOttawa", 0, []string{"oslo"}, "Ottawa"},
+		{"wrong letter falls back", "Lima", 0, nil, "Fallback"},
+		{"only used places falls back", "Oslo", 0, []string{"oslo"}, "Fallback"},
+		{"timeout falls back", "Oslo", time.Second, nil, "Fallback"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := fakeLLM(t, tt.reply, tt.delay)
+			bot := &LLMBot{
+				Provider: &OpenAIProvider{BaseURL: srv.URL, Model: "test"},
+				Fallback: fixedStrategy(fallback),
+				Timeout:  100 * time.Millisecond,
+			}
+			turn := BotTurn{LastWord: "Toronto", UsedWords: map[string]bool{"toronto": true}, Dict: dict}
+			for _, key := range tt.used {
+				turn.UsedWords[key] = true
+			}
+
+			start := time.Now()
+			got := bot.GetMove(context.Background(), turn)
+			if got.Name != tt.want {
+				t.Errorf("GetMove() = %q, want %q", got.Name, tt.want)
+			}
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("GetMove() took %s, want it cut off by the timeout", elapsed)
+			}
+		})
+	}
+}
+
+func TestBuildPromptKeepsTargetLetterUsedWords(t *testing.T) {
+	turn := BotTurn{LastWord: "Toronto", UsedWords: map[string]bool{}}
+	for i := range maxPromptUsedWords {
+		turn.UsedWords[fmt.Sprintf("zz%02d", i)] = true
+	}
+	turn.UsedWords["oslo"] = true
+	turn.UsedWords["ottawa"] = true
+
+	prompt := buildPrompt(turn)
+	for _, key := range []string{"oslo", "ottawa"} {
+		if !strings.Contains(prompt, key) {
+			t.Errorf("prompt is missing used place %q starting with the target letter", key)
+		}
+	}
+	if strings.Contains(prompt, fmt.Sprintf("zz%02d", maxPromptUsedWords-1)) {
+		t.Errorf("prompt lists more than %d used places", maxPromptUsedWords)
+	}
+}
+
+func TestLLMBotLeavesTimeForFallback(t *testing.T) {
+	dict := testDictionary(t, PlaceInfo{Name: "Oslo", Type: "City"})
+	srv := fakeLLM(t, "Oslo", time.Second)
+	bot := &LLMBot{
+		Provider: &OpenAIProvider{BaseURL: srv.URL, Model: "test"},
+		Fallback: fixedStrategy(PlaceInfo{Name: "Fallback", Type: "City"}),
+		Timeout:  DefaultLLMTimeout,
+	}
+
+	// The turn's deadline is much sooner than the bot's own timeout
+	ctx, cancel := context.WithTimeout(context.Background(), llmFallbackTime+200*time.Millisecond)
+	defer cancel()
+	got := bot.GetMove(ctx, BotTurn{Dict: dict, UsedWords: map[string]bool{}})
+	if got.Name != "Fallback" {
+		t.Errorf("GetMove() = %q, want the fallback's answer before the deadline", got.Name)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"sync"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
@@ -19,16 +20,24 @@ var upgrader = websocket.Upgrader{
 
 type Manager struct {
 	rooms map[string]*Room
-	dict  *Dictionary
+	packs *PackSet
 	um    *UserManager
+	mu    sync.RWMutex // Guards rooms and isDraining
+
+	isDraining bool // Set by Shutdown; no new rooms after that
+
+	Matchmaker *Matchmaker
+	Replays    ReplayStore // Set before serving; nil keeps no replays
 }
 
-func NewManager(dict *Dictionary, um *UserManager) *Manager {
-	return &Manager{
+func NewManager(packs *PackSet, um *UserManager) *Manager {
+	m := &Manager{
 		rooms: make(map[string]*Room),
-		dict:  dict,
+		packs: packs,
 		um:    um,
 	}
+	m.Matchmaker = NewMatchmaker(m)
+	return m
 }
 
 func (m *Manager) HandleWS(w http.ResponseWriter, r *http.Request) {
@@ -36,11 +45,55 @@ func (m *Manager) HandleWS(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
 	name := query.Get("name")
 	roomID := query.Get("room")
+	resumeToken := query.Get("resume")
+	spectate := queryBool(query.Get("spectate"))
 	
 	if name == "" {
 		name = "Guest"
 	}
 
+	if id := query.Get("replay"); id != "" {
+		m.handleReplay(w, r, id, query.Get("speed"))
+		return
+	}
+
+	if queryBool(query.Get("matchmake")) {
+		if m.draining() {
+			writeJoinError(w, errShuttingDown)
+			return
+		}
+		m.handleMatchmake(w, r, name, query.Get("mode"))
+		return
+	}
+
+	// Everything that can turn a connection away is checked before the
+	// upgrade, so clients get a plain HTTP error with the reason
+	room, ok := m.room(roomID)
+	var opts RoomOptions
+	if !ok {
+		if m.draining() {
+			writeJoinError(w, errShuttingDown)
+			return
+		}
+		var err error
+		if opts, err = ParseRoomOptions(query); err != nil {
+			writeJoinError(w, &JoinError{http.StatusBadRequest, err.Error()})
+			return
+		}
+	}
+	var existing *Player
+	canResume := false
+	if ok {
+		// A valid resume token stands in for the passcode
+		existing, canResume = room.FindByResumeToken(resumeToken)
+		if !canResume {
+			if jerr := room.CheckJoin(query.Get("passcode"), spectate); jerr != nil {
+				writeJoinError(w, jerr)
+				return
+			}
+		}
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Println(err)
@@ -48,47 +101,192 @@ func (m *Manager) HandleWS(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create or Join Room
-	if roomID == "" {
-		roomID = uuid.New().String()[:6] // Short ID
-	}
-	
-	room, ok := m.rooms[roomID]
 	if !ok {
-		room = NewRoom(roomID, m.dict, m.um)
-		m.rooms[roomID] = room
-		go room.Run()
+		var created bool
+		if room, created = m.createRoom(roomID, opts); room == nil {
+			writeJoinRejected(conn, errShuttingDown.Reason)
+			return
+		}
+		if !created {
+			// Another connection created this ID since the lookup; its
+			// passcode and caps apply, and weren't checked above
+			if jerr := room.CheckJoin(query.Get("passcode"), spectate); jerr != nil {
+				writeJoinRejected(conn, jerr.Reason)
+				return
+			}
+		}
+		roomID = room.ID
 	}
 
-	playerID := uuid.New().String()
-	player := NewPlayer(playerID, name, PlayerHuman, conn)
-	
-	room.Register <- player
+	// Resume a held seat if the token matches; otherwise join fresh
+	var player *Player
+	resumed := false
+	if canResume {
+		done := make(chan bool)
+		ev := connEvent{player: existing, conn: conn, done: done}
+		if deliver(room, room.Reattach, ev) && <-done {
+			player = existing
+			resumed = true
+		} else if jerr := room.CheckJoin(query.Get("passcode"), spectate); jerr != nil {
+			// The held seat ran out meanwhile, so this is a fresh join and
+			// the token no longer stands in for the passcode
+			writeJoinRejected(conn, jerr.Reason)
+			return
+		}
+	}
+	if player == nil {
+		playerID := uuid.New().String()
+		player = NewPlayer(playerID, name, PlayerHuman, conn)
+		player.ResumeToken = uuid.New().String()
+		player.Spectator = spectate
+		done := make(chan bool)
+		if !deliver(room, room.Register, connEvent{player: player, conn: conn, done: done}) {
+			// Closed between lookup and joining
+			writeJoinRejected(conn, "This room has closed")
+			return
+		}
+		if !<-done {
+			// Turned away; the room queued JOIN_REJECTED and closed Send
+			m.writePump(conn, player.Send)
+			return
+		}
+	}
 
 	// Send ID and RoomID to client
 	welcomeMsg := map[string]interface{}{
 		"type": "WELCOME",
-		"payload": map[string]string{
-			"id": player.ID,
-			"roomId": roomID,
+		"payload": map[string]interface{}{
+			"id":          player.ID,
+			"roomId":      roomID,
+			"resumeToken": player.ResumeToken,
+			"resumed":     resumed,
 		},
 	}
-	if err := player.Conn.WriteJSON(welcomeMsg); err != nil {
+	if err := conn.WriteJSON(welcomeMsg); err != nil {
 		log.Println("Error sending welcome:", err)
+		deliver(room, room.Disconnect, connEvent{player: player, conn: conn})
+		conn.Close()
 		return
 	}
 
-	go m.writePump(player)
-	go m.readPump(player, room)
+	go m.writePump(conn, player.Send)
+	go m.readPump(player, conn, room)
+}
+
+func (m *Manager) room(id string) (*Room, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	room, ok := m.rooms[id]
+	if ok && room.closed() {
+		return nil, false
+	}
+	return room, ok
+}
+
+// createRoom starts a room with a fresh ID, or with id if given, and reports
+// whether it made a new one. Should another connection create the same ID
+// first, that room is returned instead, with false, and opts don't apply to
+// it. It returns nil once the manager is shutting down.
+func (m *Manager) createRoom(id string, opts RoomOptions) (*Room, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.isDraining {
+		return nil, false
+	}
+	if id == "" {
+		id = newRoomID()
+	} else if room, ok := m.rooms[id]; ok && !room.closed() {
+		return room, false
+	}
+	room := NewRoom(id, m.packs.Default(), m.um)
+	room.Packs = m.packs
+	room.Replays = m.Replays
+	room.Options = opts
+	m.rooms[id] = room
+	go room.Run()
+	return room, true
+}
+
+// writeJoinRejected turns away a connection that was already upgraded when
+// it turned out it couldn't join, such as when shutdown began meanwhile.
+func writeJoinRejected(conn *websocket.Conn, reason string) {
+	conn.WriteJSON(map[string]interface{}{
+		"type":    "JOIN_REJECTED",
+		"payload": map[string]string{"reason": reason},
+	})
+	conn.Close()
+}
+
+func writeJoinError(w http.ResponseWriter, e *JoinError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(e.Status)
+	json.NewEncoder(w).Encode(map[string]string{"error": e.Reason})
+}
+
+// handleMatchmake upgrades a quick-play connection and queues it. The
+// player gets WELCOME once a room has been found for them.
+func (m *Manager) handleMatchmake(w http.ResponseWriter, r *http.Request, name, mode string) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	player := NewPlayer(uuid.New().String(), name, PlayerHuman, conn)
+	player.ResumeToken = uuid.New().String()
+	go m.writePump(conn, player.Send)
+	ticket := m.Matchmaker.Enqueue(player, mode)
+	go m.matchReadPump(ticket, conn)
+}
+
+// matchReadPump is readPump for a queued player: until they're matched the
+// only action it accepts is CANCEL_MATCHMAKING, and afterwards it feeds the
+// room like readPump.
+func (m *Manager) matchReadPump(t *matchTicket, conn *websocket.Conn) {
+	p := t.player
+	defer func() {
+		if room := m.Matchmaker.Leave(t); room != nil {
+			deliver(room, room.Disconnect, connEvent{player: p, conn: conn})
+		} else {
+			close(p.Send)
+		}
+		conn.Close()
+	}()
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var action ActionMessage
+		if err := json.Unmarshal(message, &action); err != nil {
+			continue
+		}
+		room := m.Matchmaker.roomFor(t)
+		if room == nil {
+			if action.Type == "CANCEL_MATCHMAKING" {
+				return
+			}
+			continue
+		}
+		action.PlayerID = p.ID
+		if !deliver(room, room.Action, &action) {
+			return
+		}
+	}
 }
 
-func (m *Manager) readPump(p *Player, r *Room) {
+// readPump feeds one connection's messages to the room. When the connection
+// drops the room decides whether to hold the player's seat.
+func (m *Manager) readPump(p *Player, conn *websocket.Conn, r *Room) {
 	defer func() {
-		r.Unregister <- p
-		p.Conn.Close()
+		deliver(r, r.Disconnect, connEvent{player: p, conn: conn})
+		conn.Close()
 	}()
 	
 	for {
-		_, message, err := p.Conn.ReadMessage()
+		_, message, err := conn.ReadMessage()
 		if err != nil {
 			break
 		}
@@ -96,21 +294,25 @@ func (m *Manager) readPump(p *Player, r *Room) {
 		var action ActionMessage
 		if err := json.Unmarshal(message, &action); err == nil {
 			action.PlayerID = p.ID
-			r.Action <- &action
+			if !deliver(r, r.Action, &action) {
+				return
+			}
 		}
 	}
 }
 
-func (m *Manager) writePump(p *Player) {
-	defer p.Conn.Close()
+// writePump drains send onto conn. It takes both explicitly because a
+// reconnecting player gets a new connection and channel.
+func (m *Manager) writePump(conn *websocket.Conn, send chan []byte) {
+	defer conn.Close()
 	for {
-		message, ok := <-p.Send
+		message, ok := <-send
 		if !ok {
-			p.Conn.WriteMessage(websocket.CloseMessage, []byte{})
+			conn.WriteMessage(websocket.CloseMessage, []byte{})
 			return
 		}
 		
-		w, err := p.Conn.NextWriter(websocket.TextMessage)
+		w, err := conn.NextWriter(websocket.TextMessage)
 		if err != nil {
 			return
 		}
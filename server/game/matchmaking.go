@@ -0,0 +1,271 @@
+package game
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultMatchSize    = 4
+	DefaultMatchBotWait = 30 * time.Second
+	// DefaultRatingWindow is the widest rating gap allowed between players
+	// matched straight away; it grows the longer someone waits.
+	DefaultRatingWindow = 200
+)
+
+// matchTicket is one player waiting in the quick-play queue.
+type matchTicket struct {
+	player *Player
+	mode   string
+	rating int
+	rated  bool
+	since  time.Time
+	room   *Room         // Set once matched; guarded by Matchmaker.mu
+	seated chan struct{} // Closed once room has been handed the player
+}
+
+// formedRoom is a room match has made, waiting to be handed its players,
+// bots and the order to start once the queue is unlocked.
+type formedRoom struct {
+	room  *Room
+	group []*matchTicket
+	bots  int
+}
+
+// Matchmaker groups quick-play players by preferred mode into new rooms. A
+// room forms as soon as RoomSize compatible players are waiting; anyone who
+// has waited BotWait gets a room with whoever is compatible and bots in the
+// empty seats.
+type Matchmaker struct {
+	RoomSize     int
+	BotWait      time.Duration
+	RatingWindow int
+	// Rating looks up a player's rating by name. When nil, or when it
+	// reports no rating, players are grouped regardless of skill.
+	Rating func(name string) (int, bool)
+
+	manager *Manager
+	queues  map[string][]*matchTicket // By mode, oldest first
+	wake    chan struct{}
+	start   sync.Once
+	mu      sync.Mutex
+}
+
+func NewMatchmaker(m *Manager) *Matchmaker {
+	return &Matchmaker{
+		RoomSize:     DefaultMatchSize,
+		BotWait:      DefaultMatchBotWait,
+		RatingWindow: DefaultRatingWindow,
+		manager:      m,
+		queues:       make(map[string][]*matchTicket),
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// Enqueue adds p to the queue for mode and tells them they're searching.
+func (mm *Matchmaker) Enqueue(p *Player, mode string) *matchTicket {
+	mm.start.Do(func() { go mm.run() })
+
+	mode = strings.ToUpper(mode)
+	if !gameModes[mode] {
+		mode = "CLASSIC"
+	}
+	t := &matchTicket{player: p, mode: mode, since: time.Now(), seated: make(chan struct{})}
+	if mm.Rating != nil {
+		t.rating, t.rated = mm.Rating(p.Name)
+	}
+
+	mm.mu.Lock()
+	mm.queues[mode] = append(mm.queues[mode], t)
+	waiting := len(mm.queues[mode])
+	mm.mu.Unlock()
+
+	log.Printf("[Matchmaking] %s is looking for a %s game (%d waiting)", p.Name, mode, waiting)
+	sendTo(p, "MATCHMAKING", map[string]interface{}{
+		"mode":           mode,
+		"playersWaiting": waiting,
+		"roomSize":       mm.RoomSize,
+		"botFillSeconds": int(mm.BotWait.Seconds()),
+	})
+
+	select {
+	case mm.wake <- struct{}{}:
+	default:
+	}
+	return t
+}
+
+// Leave takes t out of the queue. If t was already matched, it returns the
+// room the player was seated in instead, once the room has them.
+func (mm *Matchmaker) Leave(t *matchTicket) *Room {
+	mm.mu.Lock()
+	room := t.room
+	if room == nil {
+		mm.queues[t.mode] = removeTickets(mm.queues[t.mode], []*matchTicket{t})
+	}
+	mm.mu.Unlock()
+	if room != nil {
+		<-t.seated
+	}
+	return room
+}
+
+// roomFor returns the room t was matched into, or nil while still waiting.
+// It waits for the room to have been handed the player, so nothing the
+// player sends can reach the room before they do.
+func (mm *Matchmaker) roomFor(t *matchTicket) *Room {
+	mm.mu.Lock()
+	room := t.room
+	mm.mu.Unlock()
+	if room != nil {
+		<-t.seated
+	}
+	return room
+}
+
+func (mm *Matchmaker) run() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-mm.wake:
+		}
+		mm.match(time.Now())
+	}
+}
+
+// match forms every room it can. Starting from the longest-waiting player,
+// it gathers others close enough in rating; a full group, or any group whose
+// oldest member has waited BotWait, becomes a room. Rooms are seated after
+// the queue is unlocked, since handing a room its players blocks on the
+// room's loop.
+func (mm *Matchmaker) match(now time.Time) {
+	mm.mu.Lock()
+	formed := mm.formRooms(now)
+	mm.mu.Unlock()
+
+	for _, f := range formed {
+		f.seat()
+	}
+}
+
+// formRooms takes every group that can play now out of the queues and makes
+// a room for each. Must be called with mm.mu held.
+func (mm *Matchmaker) formRooms(now time.Time) []*formedRoom {
+	var formed []*formedRoom
+	for mode, queue := range mm.queues {
+		for i := 0; i < len(queue); {
+			anchor := queue[i]
+			waited := now.Sub(anchor.since)
+			group := []*matchTicket{anchor}
+			for _, t := range queue[i+1:] {
+				if len(group) == mm.RoomSize {
+					break
+				}
+				if mm.compatible(anchor, t, waited) {
+					group = append(group, t)
+				}
+			}
+			if len(group) < mm.RoomSize && waited < mm.BotWait {
+				i++
+				continue
+			}
+
+			f := mm.formRoom(mode, group)
+			if f == nil {
+				mm.queues[mode] = queue
+				return formed // Shutting down; nobody gets a new room
+			}
+			formed = append(formed, f)
+			queue = removeTickets(queue, group)
+		}
+		mm.queues[mode] = queue
+	}
+	return formed
+}
+
+// compatible reports whether t may share a room with anchor, who has waited
+// for waited. The allowed rating gap widens by RatingWindow for every
+// BotWait spent waiting.
+func (mm *Matchmaker) compatible(anchor, t *matchTicket, waited time.Duration) bool {
+	if !anchor.rated || !t.rated {
+		return true
+	}
+	window := mm.RatingWindow
+	if mm.BotWait > 0 {
+		window += int(float64(mm.RatingWindow) * waited.Seconds() / mm.BotWait.Seconds())
+	}
+	return abs(anchor.rating-t.rating) <= window
+}
+
+// formRoom makes a new private room for group and tells its players where
+// they're going, returning nil if no room could be created. Must be called
+// with mm.mu held; the room is seated later, by seat.
+func (mm *Matchmaker) formRoom(mode string, group []*matchTicket) *formedRoom {
+	opts := DefaultRoomOptions()
+	opts.Private = true
+	opts.MaxPlayers = max(mm.RoomSize, 2)
+	room, _ := mm.manager.createRoom("", opts)
+	if room == nil {
+		return nil
+	}
+	room.mu.Lock()
+	room.configure(roomConfig{Mode: mode})
+	room.mu.Unlock()
+	bots := opts.MaxPlayers - len(group)
+	log.Printf("[Matchmaking] Room %s formed for %d players and %d bots (%s)", room.ID, len(group), bots, mode)
+
+	for _, t := range group {
+		t.room = room
+		sendTo(t.player, "MATCH_FOUND", map[string]interface{}{
+			"roomId":  room.ID,
+			"mode":    mode,
+			"players": len(group),
+			"bots":    bots,
+		})
+		sendTo(t.player, "WELCOME", map[string]interface{}{
+			"id":          t.player.ID,
+			"roomId":      room.ID,
+			"resumeToken": t.player.ResumeToken,
+			"resumed":     false,
+		})
+	}
+	return &formedRoom{room: room, group: group, bots: bots}
+}
+
+// seat hands the room its players, fills the empty seats with bots and
+// starts the game.
+func (f *formedRoom) seat() {
+	room := f.room
+	for _, t := range f.group {
+		deliver(room, room.Register, connEvent{player: t.player, conn: t.player.Conn})
+		close(t.seated)
+	}
+
+	// The first player is the host, so start the game on their behalf
+	hostID := f.group[0].player.ID
+	for range f.bots {
+		deliver(room, room.Action, &ActionMessage{Type: "ADD_BOT", PlayerID: hostID})
+	}
+	deliver(room, room.Action, &ActionMessage{Type: "START_GAME", PlayerID: hostID})
+}
+
+func removeTickets(queue, gone []*matchTicket) []*matchTicket {
+	kept := queue[:0]
+	for _, t := range queue {
+		matched := false
+		for _, g := range gone {
+			if t == g {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,164 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// queued is a quick-play player for TestMatch: who they are, the mode they
+// asked for, their rating (0 for none) and how long they've waited.
+type queued struct {
+	name   string
+	mode   string
+	rating int
+	waited time.Duration
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		queue   []queued
+		matched []string // Who got a room, in queue order
+		rooms   int
+		bots    int // Across all the rooms formed
+	}{
+		{
+			name:    "full group",
+			queue:   []queued{{"ann", "CLASSIC", 0, 0}, {"bob", "CLASSIC", 0, 0}},
+			matched: []string{"ann", "bob"},
+			rooms:   1,
+		},
+		{
+			name:  "modes kept apart",
+			queue: []queued{{"ann", "CLASSIC", 0, 0}, {"bob", "POINT_RUSH", 0, 0}},
+		},
+		{
+			name:    "close ratings",
+			queue:   []queued{{"ann", "CLASSIC", 1000, 0}, {"bob", "CLASSIC", 1100, 0}},
+			matched: []string{"ann", "bob"},
+			rooms:   1,
+		},
+		{
+			name:  "ratings too far apart",
+			queue: []queued{{"ann", "CLASSIC", 1000, 0}, {"bob", "CLASSIC", 1150, 0}},
+		},
+		{
+			name:    "window widens with waiting",
+			queue:   []queued{{"ann", "CLASSIC", 1000, 15 * time.Second}, {"bob", "CLASSIC", 1150, 0}},
+			matched: []string{"ann", "bob"},
+			rooms:   1,
+		},
+		{
+			name:    "unrated players match anyone",
+			queue:   []queued{{"ann", "CLASSIC", 1000, 0}, {"bob", "CLASSIC", 0, 0}},
+			matched: []string{"ann", "bob"},
+			rooms:   1,
+		},
+		{
+			name:  "alone before the wait",
+			queue: []queued{{"ann", "CLASSIC", 0, 29 * time.Second}},
+		},
+		{
+			name:    "bots fill in after the wait",
+			queue:   []queued{{"ann", "CLASSIC", 0, 30 * time.Second}},
+			matched: []string{"ann"},
+			rooms:   1,
+			bots:    1,
+		},
+		{
+			name: "each mode gets its own room",
+			queue: []queued{
+				{"ann", "CLASSIC", 0, 0}, {"bob", "POINT_RUSH", 0, 0},
+				{"cat", "CLASSIC", 0, 0}, {"dan", "POINT_RUSH", 0, 0},
+			},
+			matched: []string{"ann", "bob", "cat", "dan"},
+			rooms:   2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := snapshotManager(t, testDictionary(t, PlaceInfo{Name: "Oslo", Type: "City"}))
+			mm := m.Matchmaker
+			mm.RoomSize = 2
+			mm.BotWait = 30 * time.Second
+			mm.RatingWindow = 100
+
+			now := time.Now()
+			tickets := make(map[string]*matchTicket)
+			for _, q := range tt.queue {
+				p := NewPlayer(q.name, q.name, PlayerHuman, &websocket.Conn{})
+				ticket := &matchTicket{player: p, mode: q.mode, rating: q.rating, rated: q.rating > 0, since: now.Add(-q.waited), seated: make(chan struct{})}
+				tickets[q.name] = ticket
+				mm.queues[q.mode] = append(mm.queues[q.mode], ticket)
+			}
+
+			mm.match(now)
+
+			rooms := make(map[*Room]bool)
+			var matched []string
+			for _, q := range tt.queue {
+				if room := mm.roomFor(tickets[q.name]); room != nil {
+					t.Cleanup(func() { room.Close("test over") })
+					rooms[room] = true
+					matched = append(matched, q.name)
+				}
+			}
+			if len(matched) != len(tt.matched) {
+				t.Fatalf("matched %v, want %v", matched, tt.matched)
+			}
+			for i := range matched {
+				if matched[i] != tt.matched[i] {
+					t.Fatalf("matched %v, want %v", matched, tt.matched)
+				}
+			}
+			if len(rooms) != tt.rooms {
+				t.Errorf("formed %d rooms, want %d", len(rooms), tt.rooms)
+			}
+			for room := range rooms {
+				for _, q := range tt.queue {
+					if tickets[q.name].room == room && tickets[q.name].mode != room.Mode {
+						t.Errorf("%s wanted %s but was put in a %s room", q.name, q.mode, room.Mode)
+					}
+				}
+			}
+
+			// The rooms were handed their bots and the order to start
+			// before match returned; give their loops a moment to act
+			bots := 0
+			deadline := time.Now().Add(2 * time.Second)
+			for room := range rooms {
+				for {
+					room.mu.RLock()
+					state, n := room.State, 0
+					for _, p := range room.Players {
+						if p.Type == PlayerBot {
+							n++
+						}
+					}
+					room.mu.RUnlock()
+					if state == StatePlaying || time.Now().After(deadline) {
+						if state != StatePlaying {
+							t.Errorf("room %s didn't start", room.ID)
+						}
+						bots += n
+						break
+					}
+					time.Sleep(10 * time.Millisecond)
+				}
+			}
+			if bots != tt.bots {
+				t.Errorf("rooms got %d bots, want %d", bots, tt.bots)
+			}
+
+			left := 0
+			for _, queue := range mm.queues {
+				left += len(queue)
+			}
+			if left != len(tt.queue)-len(tt.matched) {
+				t.Errorf("%d players still queued, want %d", left, len(tt.queue)-len(tt.matched))
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package game
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// StripAccents decomposes s and drops combining marks, so "São Paulo"
+// becomes "Sao Paulo". The importer uses it when cleaning source names.
+func StripAccents(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	result, _, _ := transform.String(t, s)
+	return result
+}
+
+// NormalizePlace reduces a place name to the key used for lookups and used-word
+// tracking: accents stripped, lowercased, and everything but letters and digits
+// removed. "São Paulo", "sao-paulo" and "SaoPaulo" all become "saopaulo".
+func NormalizePlace(s string) string {
+	var b strings.Builder
+	for _, r := range StripAccents(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// FirstLetter returns the first letter of the normalized name, or 0 if there
+// is none. Letters that don't decompose, like "Ł", are kept whole.
+func FirstLetter(name string) rune {
+	return firstRune(NormalizePlace(name))
+}
+
+// LastLetter returns the last letter of the normalized name, or 0 if there is
+// none.
+func LastLetter(name string) rune {
+	return lastRune(NormalizePlace(name))
+}
+
+// firstRune and lastRune are FirstLetter and LastLetter for a key that is
+// already normalized.
+func firstRune(key string) rune {
+	if key == "" {
+		return 0
+	}
+	r, _ := utf8.DecodeRuneInString(key)
+	return r
+}
+
+func lastRune(key string) rune {
+	if key == "" {
+		return 0
+	}
+	r, _ := utf8.DecodeLastRuneInString(key)
+	return r
+}
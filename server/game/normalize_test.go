@@ -0,0 +1,88 @@
+package game
+
+import "testing"
+
+func TestNormalizePlace(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"São Paulo", "saopaulo"},
+		{"sao-paulo", "saopaulo"},
+		{"SaoPaulo", "saopaulo"},
+		{"  St. John's ", "stjohns"},
+		{"Zürich", "zurich"},
+		{"Łódź", "łodz"}, // Ł doesn't decompose, so it stays
+		{"Москва", "москва"},
+		{"!?", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizePlace(tt.in); got != tt.want {
+			t.Errorf("NormalizePlace(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFirstLastLetter(t *testing.T) {
+	tests := []struct {
+		in          string
+		first, last rune
+	}{
+		{"Ålesund", 'a', 'd'},
+		{"Łódź", 'ł', 'z'},
+		{"Москва", 'м', 'а'},
+		{"Côte d'Ivoire!", 'c', 'e'},
+		{"", 0, 0},
+	}
+	for _, tt := range tests {
+		if got := FirstLetter(tt.in); got != tt.first {
+			t.Errorf("FirstLetter(%q) = %q, want %q", tt.in, got, tt.first)
+		}
+		if got := LastLetter(tt.in); got != tt.last {
+			t.Errorf("LastLetter(%q) = %q, want %q", tt.in, got, tt.last)
+		}
+	}
+}
+
+func TestLookupAliases(t *testing.T) {
+	dict := testDictionary(t,
+		PlaceInfo{Name: "Mumbai", Type: "City", Aliases: []string{"Bombay"}},
+		PlaceInfo{Name: "München", Type: "City", Aliases: []string{"Munich"}},
+		PlaceInfo{Name: "Georgia", Type: "Country"},
+		PlaceInfo{Name: "Georgia", Type: "State"},
+		// An alias never shadows a real place, and the first claim keeps it
+		PlaceInfo{Name: "Paris", Type: "City", Aliases: []string{"Georgia", "Lutetia"}},
+		PlaceInfo{Name: "Parigi", Type: "City", Aliases: []string{"Lutetia"}},
+	)
+
+	tests := []struct {
+		in       string
+		want     string
+		wantType string
+	}{
+		{"Bombay", "Mumbai", "City"},
+		{"bombay!", "Mumbai", "City"},
+		{"Munchen", "München", "City"},
+		{"MUNICH", "München", "City"},
+		{"georgia", "Georgia", "Country"},
+	}
+	for _, tt := range tests {
+		entries := dict.Lookup(tt.in)
+		if len(entries) == 0 {
+			t.Errorf("Lookup(%q) found nothing, want %s", tt.in, tt.want)
+			continue
+		}
+		if entries[0].Name != tt.want || entries[0].Type != tt.wantType {
+			t.Errorf("Lookup(%q) = %s (%s), want %s (%s)", tt.in, entries[0].Name, entries[0].Type, tt.want, tt.wantType)
+		}
+	}
+
+	if entries := dict.Lookup("Lutetia"); len(entries) != 1 {
+		t.Errorf("Lookup(Lutetia) = %v, want a single place", entries)
+	}
+	if entries := dict.Lookup("Gotham"); len(entries) != 0 {
+		t.Errorf("Lookup(Gotham) = %v, want nothing", entries)
+	}
+	if _, ok := dict.Resolve("Georgia", "state"); !ok {
+		t.Errorf("Resolve(Georgia, state) found nothing")
+	}
+}
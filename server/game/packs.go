@@ -0,0 +1,138 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultPack is the pack a room plays with unless START_GAME names another.
+const DefaultPack = "world"
+
+// PackSet holds the named dictionaries ("world", "europe", "us-cities", ...)
+// a room can choose from. The default pack comes from a single file; extra
+// packs are every *.json file in a directory, named after the file.
+type PackSet struct {
+	defaultPath string
+	dir         string
+	packs       map[string]*Dictionary
+	mu          sync.RWMutex
+}
+
+// LoadPacks loads the default pack from defaultPath and any extra packs from
+// dir. A missing dir is not an error.
+func LoadPacks(defaultPath, dir string) (*PackSet, error) {
+	ps := &PackSet{
+		defaultPath: defaultPath,
+		dir:         dir,
+		packs:       make(map[string]*Dictionary),
+	}
+	if err := ps.Reload(); err != nil {
+		return nil, err
+	}
+	return ps, nil
+}
+
+// Reload refreshes every pack in place, picks up packs added to the
+// directory since the last load and drops packs whose files are gone.
+// Dictionaries already handed to rooms are updated atomically rather than
+// replaced, so running games switch over on their next lookup; a game on a
+// dropped pack finishes with the places it had. Packs that fail to load keep
+// their previous data.
+func (ps *PackSet) Reload() error {
+	files := map[string]string{DefaultPack: ps.defaultPath}
+	if ps.dir != "" {
+		matches, err := filepath.Glob(filepath.Join(ps.dir, "*.json"))
+		if err != nil {
+			return err
+		}
+		for _, path := range matches {
+			name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+			if name != DefaultPack {
+				files[name] = path
+			}
+		}
+	}
+
+	var errs []error
+	for name, path := range files {
+		ps.mu.RLock()
+		existing, ok := ps.packs[name]
+		ps.mu.RUnlock()
+
+		if ok {
+			if err := existing.Reload(); err != nil {
+				if name != DefaultPack && os.IsNotExist(err) {
+					delete(files, name) // Removed since the glob
+				} else {
+					errs = append(errs, fmt.Errorf("pack %q: %w", name, err))
+				}
+			}
+			continue
+		}
+
+		dict, err := NewDictionary(path)
+		if err != nil {
+			if name == DefaultPack || !os.IsNotExist(err) {
+				errs = append(errs, fmt.Errorf("pack %q: %w", name, err))
+			}
+			continue
+		}
+		ps.mu.Lock()
+		ps.packs[name] = dict
+		ps.mu.Unlock()
+	}
+
+	ps.mu.Lock()
+	for name := range ps.packs {
+		if _, onDisk := files[name]; !onDisk {
+			delete(ps.packs, name)
+		}
+	}
+	ps.mu.Unlock()
+	return errors.Join(errs...)
+}
+
+// Get returns the named pack; an empty name means the default pack.
+func (ps *PackSet) Get(name string) (*Dictionary, bool) {
+	if name == "" {
+		name = DefaultPack
+	}
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	dict, ok := ps.packs[name]
+	return dict, ok
+}
+
+// Default returns the default pack.
+func (ps *PackSet) Default() *Dictionary {
+	dict, _ := ps.Get(DefaultPack)
+	return dict
+}
+
+// Sizes reports how many place names each loaded pack holds.
+func (ps *PackSet) Sizes() map[string]int {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	sizes := make(map[string]int, len(ps.packs))
+	for name, dict := range ps.packs {
+		sizes[name] = dict.Len()
+	}
+	return sizes
+}
+
+// Names lists the loaded packs in alphabetical order.
+func (ps *PackSet) Names() []string {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	names := make([]string, 0, len(ps.packs))
+	for name := range ps.packs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,56 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPackSetReload(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	defaultPath := write("world.json", `[{"name": "Paris", "type": "City"}]`)
+	packDir := filepath.Join(dir, "packs")
+	if err := os.Mkdir(packDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	europe := write("packs/europe.json", `[{"name": "Oslo", "type": "City"}]`)
+
+	ps, err := LoadPacks(defaultPath, packDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ps.Names(); !reflect.DeepEqual(got, []string{"europe", "world"}) {
+		t.Fatalf("Names() = %v", got)
+	}
+	held, _ := ps.Get("europe")
+
+	write("packs/asia.json", `[{"name": "Tokyo", "type": "City"}]`)
+	if err := os.Remove(europe); err != nil {
+		t.Fatal(err)
+	}
+	if err := ps.Reload(); err != nil {
+		t.Fatalf("Reload() = %v", err)
+	}
+	if got := ps.Names(); !reflect.DeepEqual(got, []string{"asia", "world"}) {
+		t.Errorf("after Reload, Names() = %v, want [asia world]", got)
+	}
+	if got := ps.Sizes(); !reflect.DeepEqual(got, map[string]int{"asia": 1, "world": 1}) {
+		t.Errorf("after Reload, Sizes() = %v", got)
+	}
+	if _, ok := ps.Get("europe"); ok {
+		t.Errorf("removed pack is still selectable")
+	}
+	// A room already playing the removed pack keeps its places
+	if len(held.Lookup("Oslo")) == 0 {
+		t.Errorf("held dictionary lost its places")
+	}
+}
@@ -21,6 +21,10 @@ type Player struct {
 	IsTurn         bool            `json:"isTurn"`
 	AvatarURL      string          `json:"avatarUrl"`
 	MostUsedPlaces map[string]int  `json:"mostUsedPlaces"`
+	BotLevel       BotDifficulty   `json:"botLevel,omitempty"` // Only set for bots
+	Connected      bool            `json:"connected"`          // False while a human's seat is held for reconnection
+	ResumeToken    string          `json:"-"`                  // Lets a dropped human reclaim this seat
+	Spectator      bool            `json:"spectator"`          // Watches the game without taking turns
 	// Channel to send messages to this player
 	Send chan []byte `json:"-"`
 }
@@ -33,6 +37,7 @@ func NewPlayer(id, name string, pType PlayerType, conn *websocket.Conn) *Player
 		Conn:           conn,
 		Lives:          3,
 		MostUsedPlaces: make(map[string]int),
+		Connected:      true,
 		Send:           make(chan []byte, 256),
 	}
 }
\ No newline at end of file
@@ -0,0 +1,303 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	maxReplaySpeed = 16.0
+	// maxReplayGap caps how long playback sits on one pause, so a game
+	// where everyone wandered off doesn't stall the viewer.
+	maxReplayGap = 10 * time.Second
+)
+
+// Replay is the record of one finished game.
+type Replay struct {
+	ID        string         `json:"id"`
+	RoomID    string         `json:"roomId"`
+	Mode      string         `json:"mode"`
+	Pack      string         `json:"pack"`
+	Filter    PlaceFilter    `json:"filter"`
+	Settings  map[string]int `json:"settings"`
+	StartedAt time.Time      `json:"startedAt"`
+	EndedAt   time.Time      `json:"endedAt"`
+	Players   []ReplayPlayer `json:"players"` // Seated at the start, in turn order
+	WinnerID  string         `json:"winnerId,omitempty"`
+	Events    []ReplayEvent  `json:"events,omitempty"`
+}
+
+type ReplayPlayer struct {
+	ID       string        `json:"id"`
+	Name     string        `json:"name"`
+	Type     PlayerType    `json:"type"`
+	BotLevel BotDifficulty `json:"botLevel,omitempty"`
+}
+
+// ReplayEvent is one thing that happened during a game. Type is one of
+// GAME_STARTED, PLAYER_JOINED, PLAYER_LEFT, WORD_ACCEPTED, WORD_REJECTED,
+// TURN_FORFEITED (a bot gave up), TURN_TIMEOUT, CHAT or GAME_ENDED.
+type ReplayEvent struct {
+	Seq        int    `json:"seq"`
+	AtMs       int64  `json:"atMs"` // Since the game started
+	Type       string `json:"type"`
+	PlayerID   string `json:"playerId,omitempty"`
+	PlayerName string `json:"playerName,omitempty"`
+	Word       string `json:"word,omitempty"`   // As submitted, for WORD_REJECTED
+	Reason     string `json:"reason,omitempty"` // Why a word was rejected
+	Move       *Move  `json:"move,omitempty"`   // WORD_ACCEPTED only
+	Points     int    `json:"points,omitempty"`
+	Message    string `json:"message,omitempty"` // CHAT only
+	Spectator  bool   `json:"spectator,omitempty"`
+}
+
+// startReplay begins recording the game that is starting. Must be called
+// with r.mu held.
+func (r *Room) startReplay() {
+	r.replay = &Replay{
+		ID:        uuid.New().String(),
+		RoomID:    r.ID,
+		Mode:      r.Mode,
+		Pack:      r.Pack,
+		Filter:    r.Filter,
+		Settings:  r.Settings,
+		StartedAt: time.Now(),
+	}
+	for _, id := range r.TurnOrder {
+		p := r.Players[id]
+		r.replay.Players = append(r.replay.Players, ReplayPlayer{ID: p.ID, Name: p.Name, Type: p.Type, BotLevel: p.BotLevel})
+	}
+	r.record(ReplayEvent{Type: "GAME_STARTED"})
+}
+
+// record appends ev to the game being recorded, if any. Must be called with
+// r.mu held.
+func (r *Room) record(ev ReplayEvent) {
+	if r.replay == nil {
+		return
+	}
+	ev.Seq = len(r.replay.Events) + 1
+	ev.AtMs = time.Since(r.replay.StartedAt).Milliseconds()
+	r.replay.Events = append(r.replay.Events, ev)
+}
+
+// replayEvent is how ev appears in a replay. GameStarted and GameTimedOut
+// have no entry of their own; the recording's start and GAME_ENDED cover
+// them. Must be called with r.mu held, before ev is applied.
+func (r *Room) replayEvent(ev GameEvent) (ReplayEvent, bool) {
+	name := func(id string) string {
+		if p, ok := r.Players[id]; ok {
+			return p.Name
+		}
+		return ""
+	}
+	switch e := ev.(type) {
+	case WordAccepted:
+		move := e.Move
+		return ReplayEvent{Type: "WORD_ACCEPTED", PlayerID: e.PlayerID, PlayerName: name(e.PlayerID), Move: &move, Points: e.Points}, true
+	case WordRejected:
+		return ReplayEvent{Type: "WORD_REJECTED", PlayerID: e.PlayerID, PlayerName: name(e.PlayerID), Word: e.Word, Reason: e.Reason}, true
+	case TurnTimedOut:
+		return ReplayEvent{Type: "TURN_TIMEOUT", PlayerID: e.PlayerID, PlayerName: name(e.PlayerID)}, true
+	case TurnForfeited:
+		return ReplayEvent{Type: "TURN_FORFEITED", PlayerID: e.PlayerID, PlayerName: name(e.PlayerID)}, true
+	case PlayerLeft:
+		return ReplayEvent{Type: "PLAYER_LEFT", PlayerID: e.PlayerID, PlayerName: name(e.PlayerID)}, true
+	}
+	return ReplayEvent{}, false
+}
+
+// Rebuild replays the recording through the game rules, returning the game
+// as it stood at the end.
+func (rep *Replay) Rebuild() Game {
+	order := make([]string, len(rep.Players))
+	for i, p := range rep.Players {
+		order[i] = p.ID
+	}
+	g, _ := Apply(Game{}, GameStarted{Mode: rep.Mode, Order: order})
+	for _, rev := range rep.Events {
+		var ev GameEvent
+		switch rev.Type {
+		case "WORD_ACCEPTED":
+			if rev.Move == nil {
+				continue
+			}
+			ev = WordAccepted{PlayerID: rev.PlayerID, Move: *rev.Move, Points: rev.Points}
+		case "WORD_REJECTED":
+			ev = WordRejected{PlayerID: rev.PlayerID, Word: rev.Word, Reason: rev.Reason}
+		case "TURN_TIMEOUT":
+			ev = TurnTimedOut{PlayerID: rev.PlayerID}
+		case "TURN_FORFEITED":
+			ev = TurnForfeited{PlayerID: rev.PlayerID}
+		case "PLAYER_LEFT":
+			ev = PlayerLeft{PlayerID: rev.PlayerID}
+		case "GAME_ENDED":
+			// Only the game clock ends a game without an event of its own;
+			// otherwise the game is already over and this does nothing
+			ev = GameTimedOut{}
+		default:
+			continue
+		}
+		g, _ = Apply(g, ev)
+	}
+	return g
+}
+
+// finishReplay closes the recording with the result and saves it. Calling it
+// again for the same game does nothing. Must be called with r.mu held.
+func (r *Room) finishReplay(winnerID string) {
+	rep := r.replay
+	if rep == nil {
+		return
+	}
+	ev := ReplayEvent{Type: "GAME_ENDED", PlayerID: winnerID}
+	if p, ok := r.Players[winnerID]; ok {
+		ev.PlayerName = p.Name
+	}
+	r.record(ev)
+	rep.WinnerID = winnerID
+	rep.EndedAt = time.Now()
+	r.replay = nil
+	r.lastReplayID = rep.ID
+
+	if r.Replays == nil {
+		return
+	}
+	// The recording is no longer touched, so save it off the room loop
+	go func() {
+		if err := r.Replays.Save(rep); err != nil {
+			log.Printf("[Room %s] Saving replay %s: %v", r.ID, rep.ID, err)
+		}
+	}()
+}
+
+// ErrReplayNotFound is returned by ReplayStore.Get for an unknown ID.
+var ErrReplayNotFound = errors.New("replay not found")
+
+// ReplayStore keeps finished games.
+type ReplayStore interface {
+	Save(rep *Replay) error
+	Get(id string) (*Replay, error)
+}
+
+// FileReplayStore keeps each replay as <Dir>/<replay id>.json.
+type FileReplayStore struct {
+	Dir string
+}
+
+func NewFileReplayStore(dir string) *FileReplayStore {
+	return &FileReplayStore{Dir: dir}
+}
+
+func (fs *FileReplayStore) path(id string) string {
+	return filepath.Join(fs.Dir, filepath.Base(id)+".json")
+}
+
+func (fs *FileReplayStore) Save(rep *Replay) error {
+	if err := os.MkdirAll(fs.Dir, 0755); err != nil {
+		return err
+	}
+	return writeJSONFile(fs.path(rep.ID), rep)
+}
+
+func (fs *FileReplayStore) Get(id string) (*Replay, error) {
+	if id == "" {
+		return nil, ErrReplayNotFound
+	}
+	data, err := os.ReadFile(fs.path(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrReplayNotFound
+		}
+		return nil, err
+	}
+	var rep Replay
+	if err := json.Unmarshal(data, &rep); err != nil {
+		return nil, err
+	}
+	return &rep, nil
+}
+
+// GetReplay looks up a finished game.
+func (m *Manager) GetReplay(id string) (*Replay, error) {
+	if m.Replays == nil {
+		return nil, ErrReplayNotFound
+	}
+	return m.Replays.Get(id)
+}
+
+// handleReplay plays a finished game back over a websocket: REPLAY_START with
+// the game's details, each event as REPLAY_EVENT spaced as it originally
+// was, divided by speed, then REPLAY_END.
+func (m *Manager) handleReplay(w http.ResponseWriter, r *http.Request, id, speedParam string) {
+	speed := 1.0
+	if speedParam != "" {
+		s, err := strconv.ParseFloat(speedParam, 64)
+		if err != nil || s <= 0 || s > maxReplaySpeed {
+			writeJoinError(w, &JoinError{http.StatusBadRequest, "speed must be above 0 and at most 16"})
+			return
+		}
+		speed = s
+	}
+	rep, err := m.GetReplay(id)
+	if err != nil {
+		if errors.Is(err, ErrReplayNotFound) {
+			writeJoinError(w, &JoinError{http.StatusNotFound, "Replay not found"})
+		} else {
+			writeJoinError(w, &JoinError{http.StatusInternalServerError, err.Error()})
+		}
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	defer conn.Close()
+
+	// Reading is only for noticing the viewer has gone
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	header := *rep
+	header.Events = nil
+	if err := conn.WriteJSON(map[string]interface{}{
+		"type":    "REPLAY_START",
+		"payload": map[string]interface{}{"replay": header, "events": len(rep.Events), "speed": speed},
+	}); err != nil {
+		return
+	}
+
+	var prev int64
+	for _, ev := range rep.Events {
+		gap := min(time.Duration(ev.AtMs-prev)*time.Millisecond, maxReplayGap)
+		select {
+		case <-gone:
+			return
+		case <-time.After(time.Duration(float64(gap) / speed)):
+		}
+		if err := conn.WriteJSON(map[string]interface{}{"type": "REPLAY_EVENT", "payload": ev}); err != nil {
+			return
+		}
+		prev = ev.AtMs
+	}
+	conn.WriteJSON(map[string]interface{}{"type": "REPLAY_END", "payload": map[string]string{"id": rep.ID}})
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+}
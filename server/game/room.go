@@ -1,12 +1,14 @@
 package game
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 )
@@ -19,12 +21,31 @@ const (
 	StateEnded   GameState = "ENDED"
 )
 
+// DefaultTurnSeconds is the per-turn time limit used when a game does not set
+// Settings["turnSeconds"].
+const DefaultTurnSeconds = 30
+
+// DefaultPointRushSeconds is the whole-game clock for POINT_RUSH when
+// Settings["timeLimit"] is not set.
+const DefaultPointRushSeconds = 300
+
+// botTurnSlack is how long before the turn deadline a bot must have answered.
+const botTurnSlack = 2 * time.Second
+
 type Move struct {
-	PlayerID   string `json:"playerId"`
-	PlayerName string `json:"playerName"`
-	Word       string `json:"word"`
-	Type       string `json:"type"` // City, Country, etc.
-	Timestamp  int64  `json:"timestamp"`
+	PlayerID      string `json:"playerId"`
+	PlayerName    string `json:"playerName"`
+	Word          string `json:"word"`
+	Type          string `json:"type"` // City, Country, etc.
+	Timestamp     int64  `json:"timestamp"`
+	CorrectedFrom string `json:"correctedFrom,omitempty"` // What was typed, if auto-corrected
+
+	// Place metadata, when the dictionary has it
+	CountryCode string  `json:"countryCode,omitempty"`
+	Admin       string  `json:"admin,omitempty"`
+	Lat         float64 `json:"lat,omitempty"`
+	Lon         float64 `json:"lon,omitempty"`
+	Population  int64   `json:"population,omitempty"`
 }
 
 type ChatMessage struct {
@@ -37,6 +58,7 @@ type ChatMessage struct {
 type Room struct {
 	ID               string
 	Players          map[string]*Player
+	Spectators       map[string]*Player // Watch and chat; never take turns
 	TurnOrder        []string
 	CurrentTurnIndex int
 	State            GameState
@@ -46,19 +68,62 @@ type Room struct {
 	Round            int    `json:"round"`
 	ChatHistory      []ChatMessage `json:"chatHistory"`
 	
+	// Settings keys: "timeLimit" and "turnSeconds" (seconds), "autoCorrect"
+	// (largest typo distance silently accepted), "suggestions" (near matches
+	// listed when a word is rejected).
 	Mode     string         `json:"mode"`     // CLASSIC, POINT_RUSH, SUDDEN_DEATH
 	Settings map[string]int `json:"settings"` // e.g., "timeLimit": 300
+	Filter   PlaceFilter    `json:"filter"`   // Category restriction, e.g. Countries only
+	LateJoin string         `json:"lateJoin"` // What happens to players joining mid-game
+	HostID   string         `json:"hostId"`   // Only the host may start, configure or moderate
+	Options  RoomOptions    `json:"options"`  // Fixed when the room is created
 
 	Dict        *Dictionary
-	BotBrain    *Bot
+	Pack        string      // Name of the data pack Dict came from
+	Packs       *PackSet    // Packs START_GAME may choose from; nil keeps Dict
+	Replays     ReplayStore // Where finished games are saved; nil discards them
+	BotBrain    BotStrategy
 	UserManager *UserManager
 	TurnStartTime time.Time
-
-	Register   chan *Player
-	Unregister chan *Player
-	Broadcast  chan []byte
+	TurnDeadline  time.Time
+	GameDeadline  time.Time
+
+	// turnTimer fires when the current turn's deadline passes and gameTimer
+	// when the whole-game clock runs out. Both are only touched from the Run
+	// goroutine.
+	turnTimer *time.Timer
+	gameTimer *time.Timer
+
+	// turn mirrors Game.Turn, so apply can tell when the turn has passed.
+	turn int
+
+	// botCancel abandons the bot turn in progress for botThinking, so a
+	// stale answer never lands after the turn has moved on.
+	botCancel   context.CancelFunc
+	botThinking string
+
+	// lateJoiners are spectators waiting to be seated when the next game
+	// starts, in arrival order.
+	lateJoiners []string
+
+	// replay records the game in progress; lastReplayID names the most
+	// recently finished one.
+	replay       *Replay
+	lastReplayID string
+
+	// done is closed by Close to stop Run, and stopped by Run once it has
+	// returned; idleSince is when the room last lost its final connected
+	// human, for the reaper.
+	done        chan struct{}
+	stopped     chan struct{}
+	closeOnce   sync.Once
+	closeReason string
+	idleSince   time.Time
+
+	Register   chan connEvent
+	Disconnect chan connEvent
+	Reattach   chan connEvent
 	Action     chan *ActionMessage
-	Chat       chan *ActionMessage
 
 	mu sync.RWMutex
 }
@@ -73,18 +138,23 @@ func NewRoom(id string, dict *Dictionary, um *UserManager) *Room {
 	return &Room{
 		ID:          id,
 		Players:     make(map[string]*Player),
+		Spectators:  make(map[string]*Player),
 		UsedWords:   make(map[string]bool),
 		State:       StateWaiting,
 		Mode:        "CLASSIC",
 		Settings:    make(map[string]int),
+		LateJoin:    DefaultLateJoin,
+		Options:     DefaultRoomOptions(),
 		Dict:        dict,
-		BotBrain:    NewBot(dict),
+		Pack:        DefaultPack,
+		BotBrain:    NewBotStrategyFromEnv(),
 		UserManager: um,
-		Register:    make(chan *Player),
-		Unregister:  make(chan *Player),
-		Broadcast:   make(chan []byte),
+		Register:    make(chan connEvent),
+		Disconnect:  make(chan connEvent),
+		Reattach:    make(chan connEvent),
 		Action:      make(chan *ActionMessage),
-		Chat:        make(chan *ActionMessage),
+		done:        make(chan struct{}),
+		stopped:     make(chan struct{}),
 		History:     []Move{},
 		ChatHistory: []ChatMessage{},
 		Round:       1,
@@ -92,74 +162,69 @@ func NewRoom(id string, dict *Dictionary, um *UserManager) *Room {
 }
 
 func (r *Room) Run() {
+	defer close(r.stopped)
 	for {
 		select {
-		case player := <-r.Register:
+		case ev := <-r.Register:
 			r.mu.Lock()
-			r.Players[player.ID] = player
-			r.TurnOrder = append(r.TurnOrder, player.ID)
+			admitted := r.admit(ev.player)
 			r.mu.Unlock()
-			r.broadcastState()
-
-		case player := <-r.Unregister:
-			r.mu.Lock()
-			if _, ok := r.Players[player.ID]; ok {
-				delete(r.Players, player.ID)
-				close(player.Send)
-
-				removedIndex := -1
-				for i, pid := range r.TurnOrder {
-					if pid == player.ID {
-						removedIndex = i
-						break
-					}
-				}
-
-				if removedIndex != -1 {
-					r.TurnOrder = append(r.TurnOrder[:removedIndex], r.TurnOrder[removedIndex+1:]...)
-					
-					if len(r.TurnOrder) == 0 {
-						r.CurrentTurnIndex = 0
-						r.State = StateWaiting
-					} else {
-						if r.CurrentTurnIndex > removedIndex {
-							r.CurrentTurnIndex--
-						} else if r.CurrentTurnIndex == removedIndex {
-							r.CurrentTurnIndex = r.CurrentTurnIndex % len(r.TurnOrder)
-						}
-					}
-				}
-
-				if r.State == StatePlaying {
-					r.checkGameOver()
-				}
+			if ev.done != nil {
+				ev.done <- admitted
 			}
-			r.mu.Unlock()
 			r.broadcastState()
 
-		case message := <-r.Broadcast:
-			r.mu.RLock()
-			for _, player := range r.Players {
-				if player.Type == PlayerHuman {
-					select {
-					case player.Send <- message:
-					default:
-						close(player.Send)
-						delete(r.Players, player.ID)
-					}
-				}
-			}
-			r.mu.RUnlock()
+		case ev := <-r.Disconnect:
+			r.handleDisconnect(ev)
 
-		case chatMsg := <-r.Chat:
-			r.handleChatMessage(chatMsg)
+		case ev := <-r.Reattach:
+			r.handleReattach(ev)
 
 		case action := <-r.Action:
 			r.handleAction(action)
+
+		case <-r.turnTimeout():
+			r.handleTurnTimeout()
+
+		case <-r.gameTimeout():
+			r.handleGameTimeout()
+
+		case <-r.done:
+			r.shutdown()
+			return
 		}
 	}
 }
 
+// removePlayer takes a player out of the room for good, fixing up the turn
+// order around them. Must be called with r.mu held.
+func (r *Room) removePlayer(player *Player) {
+	if _, ok := r.Spectators[player.ID]; ok {
+		delete(r.Spectators, player.ID)
+		r.dequeue(player.ID)
+		close(player.Send)
+	} else if _, ok := r.Players[player.ID]; ok {
+		r.leaveGame(player)
+		close(player.Send)
+	} else {
+		return
+	}
+	if player.ID == r.HostID {
+		r.reassignHost()
+	}
+}
+
+// leaveGame takes a player out of the player list and turn order, passing
+// the turn on if it was theirs. Must be called with r.mu held.
+func (r *Room) leaveGame(player *Player) {
+	if _, ok := r.Players[player.ID]; !ok {
+		return
+	}
+	if !r.apply(PlayerLeft{PlayerID: player.ID}) {
+		delete(r.Players, player.ID) // Never made it into the turn order
+	}
+}
+
 func (r *Room) handleChatMessage(msg *ActionMessage) {
 	var p struct {
 		Message string `json:"message"`
@@ -169,9 +234,9 @@ func (r *Room) handleChatMessage(msg *ActionMessage) {
 	}
 
 	r.mu.Lock()
-	player := r.Players[msg.PlayerID]
-	if player == nil {
-		r.mu.Unlock()
+	defer r.mu.Unlock()
+	player, ok := r.member(msg.PlayerID)
+	if !ok {
 		return
 	}
 
@@ -183,60 +248,120 @@ func (r *Room) handleChatMessage(msg *ActionMessage) {
 	}
 
 	r.ChatHistory = append(r.ChatHistory, chatMsg)
-	r.mu.Unlock()
+	r.record(ReplayEvent{Type: "CHAT", PlayerID: chatMsg.PlayerID, PlayerName: chatMsg.PlayerName, Message: chatMsg.Message})
 
 	// Broadcast chat message
-	response := map[string]interface{}{
-		"type":    "CHAT_MESSAGE",
-		"payload": chatMsg,
-	}
-	data, _ := json.Marshal(response)
-	r.Broadcast <- data
+	r.broadcastEventInternal("CHAT_MESSAGE", chatMsg)
 }
 
 func (r *Room) handleAction(action *ActionMessage) {
+	r.mu.RLock()
+	_, isSpectator := r.Spectators[action.PlayerID]
+	isHost := action.PlayerID == r.HostID
+	r.mu.RUnlock()
+	if hostActions[action.Type] && !isHost {
+		r.mu.RLock()
+		r.sendError(action.PlayerID, "Only the host can do that")
+		r.mu.RUnlock()
+		return
+	}
+	if isSpectator && !spectatorActions[action.Type] && !hostActions[action.Type] {
+		return
+	}
+
 	switch action.Type {
 	case "START_GAME":
-		var p struct {
-			Mode     string         `json:"mode"`
-			Settings map[string]int `json:"settings"`
+		// Same payload as UPDATE_SETTINGS; omitted fields keep the lobby's
+		// current configuration
+		var cfg roomConfig
+		_ = json.Unmarshal(action.Payload, &cfg)
+		r.mu.Lock()
+		if r.State == StatePlaying {
+			r.sendError(action.PlayerID, "A game is already in progress")
+			r.mu.Unlock()
+			return
 		}
-		if err := json.Unmarshal(action.Payload, &p); err == nil {
-			r.startGame(p.Mode, p.Settings)
-		} else {
-			r.startGame("CLASSIC", nil)
+		err := r.configure(cfg)
+		if err != nil {
+			r.sendError(action.PlayerID, err.Error())
+		}
+		r.mu.Unlock()
+		if err == nil {
+			r.startGame()
 		}
 	case "ADD_BOT":
-		r.addBot()
+		var p struct {
+			Difficulty string `json:"difficulty"`
+		}
+		_ = json.Unmarshal(action.Payload, &p) // No payload means MEDIUM
+		r.addBot(action.PlayerID, ParseBotDifficulty(p.Difficulty))
 	case "SUBMIT_WORD":
-		var p struct{ Word string `json:"word"` }
+		var p struct {
+			Word string `json:"word"`
+			Type string `json:"type"` // Optional: which same-named place was meant
+		}
 		if err := json.Unmarshal(action.Payload, &p); err == nil {
-			r.processTurn(action.PlayerID, p.Word)
+			r.processTurn(action.PlayerID, p.Word, p.Type)
+		}
+	case "BOT_ANSWER":
+		var move PlaceInfo
+		if err := json.Unmarshal(action.Payload, &move); err == nil {
+			r.applyBotAnswer(action.PlayerID, move)
 		}
-	case "BOT_MOVE":
-		r.processBotTurn(action.PlayerID)
 	case "CHAT":
-		r.Chat <- action
+		r.handleChatMessage(action)
+	case "JOIN_AS_SPECTATOR":
+		r.setSpectator(action.PlayerID, true)
+	case "JOIN_AS_PLAYER":
+		r.setSpectator(action.PlayerID, false)
+	case "KICK_PLAYER", "REMOVE_BOT", "TRANSFER_HOST", "UPDATE_SETTINGS", "RESET_GAME":
+		r.mu.Lock()
+		r.handleHostAction(action)
+		r.mu.Unlock()
 	}
 }
 
-func (r *Room) addBot() {
+func (r *Room) addBot(requestedBy string, level BotDifficulty) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	if !r.Options.AllowBots {
+		r.sendError(requestedBy, "Bots aren't allowed in this room")
+		return
+	}
+	// Like anyone else, a bot only takes a seat between games
+	if r.State == StatePlaying {
+		r.sendError(requestedBy, "Bots can only be added between games")
+		return
+	}
+	if r.seatsFull() {
+		r.sendError(requestedBy, errRoomFull.Reason)
+		return
+	}
+
 	botID := uuid.New().String()
-	name := fmt.Sprintf("Bot-%s", botID[:4])
+	name := fmt.Sprintf("Bot-%s [%s]", botID[:4], level)
 
 	botPlayer := NewPlayer(botID, name, PlayerBot, nil)
+	botPlayer.BotLevel = level
 	r.Players[botID] = botPlayer
 	r.TurnOrder = append(r.TurnOrder, botID)
 
-	go r.broadcastState()
+	r.broadcastStateInternal()
 }
 
-func (r *Room) startGame(mode string, settings map[string]int) {
+// startGame begins a game with the room's configured mode, settings, data
+// pack, filter and late-join policy.
+func (r *Room) startGame() {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+
+	if r.State == StatePlaying {
+		return
+	}
+
+	// Whoever queued during the last game gets a seat in this one
+	r.seatLateJoiners()
 	
 	// Require at least 2 players (can be bot + human)
 	if len(r.Players) < 2 {
@@ -254,102 +379,115 @@ func (r *Room) startGame(mode string, settings map[string]int) {
 		return
 	}
 	
-	if mode != "" {
-		r.Mode = mode
-	} else {
-		r.Mode = "CLASSIC"
+	r.apply(GameStarted{Mode: r.Mode, Order: r.TurnOrder})
+}
+
+// scheduleBotTurn starts the current player's bot deliberating, if the turn
+// belongs to a bot. The strategy runs off the room loop, humans see a
+// BOT_TYPING event, and the answer arrives as a BOT_ANSWER action once the
+// bot's simulated thinking time has passed. Any earlier pending bot turn is
+// cancelled. Must be called with r.mu held.
+func (r *Room) scheduleBotTurn() {
+	r.cancelBotTurn()
+	if r.State != StatePlaying || len(r.TurnOrder) == 0 {
+		return
 	}
-	
-	if settings != nil {
-		r.Settings = settings
-	} else {
-		r.Settings = make(map[string]int)
+	playerID := r.TurnOrder[r.CurrentTurnIndex]
+	player := r.Players[playerID]
+	if player.Type != PlayerBot {
+		return
 	}
 
-	r.State = StatePlaying
-	r.CurrentTurnIndex = 0
-	r.UsedWords = make(map[string]bool)
-	r.LastWord = ""
-	r.History = []Move{}
-	r.Round = 1
-	r.TurnStartTime = time.Now()
-
-	for _, p := range r.Players {
-		p.Lives = 3
-		if r.Mode == "SUDDEN_DEATH" {
-			p.Lives = 1
-		}
-		p.IsTurn = false
-		p.Score = 0
+	turn := BotTurn{
+		LastWord:  r.LastWord,
+		UsedWords: make(map[string]bool, len(r.UsedWords)),
+		Level:     player.BotLevel,
+		Dict:      r.Dict,
+		Filter:    r.Filter,
 	}
+	for k, v := range r.UsedWords {
+		turn.UsedWords[k] = v
+	}
+	strategy := r.BotBrain
+	// Leave the bot a little slack so it never loses a life to the clock;
+	// a strategy that's still thinking by then has to settle for something
+	deadline := r.TurnDeadline.Add(-botTurnSlack)
+	if r.TurnDeadline.IsZero() {
+		deadline = time.Now().Add(r.turnDuration() - botTurnSlack)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.botCancel = cancel
+	r.botThinking = playerID
+	r.broadcastEventInternal("BOT_TYPING", map[string]interface{}{"playerId": playerID, "typing": true})
+
+	go func() {
+		start := time.Now()
+		log.Printf("[Bot] Thinking for last word: %s", turn.LastWord)
+		moveCtx, stop := context.WithDeadline(ctx, deadline)
+		move := strategy.GetMove(moveCtx, turn)
+		stop()
+		log.Printf("[Bot] Decided: %s", move.Name)
+
+		answerAt := start.Add(botThinkTime(turn, move))
+		if answerAt.After(deadline) {
+			answerAt = deadline
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(answerAt)):
+		}
 
-	firstPlayerID := r.TurnOrder[0]
-	r.Players[firstPlayerID].IsTurn = true
-
-	r.broadcastStateInternal()
+		payload, _ := json.Marshal(move)
+		select {
+		case r.Action <- &ActionMessage{Type: "BOT_ANSWER", PlayerID: playerID, Payload: payload}:
+		case <-ctx.Done():
+		case <-r.done:
+		}
+	}()
+}
 
-	if r.Players[firstPlayerID].Type == PlayerBot {
-		go func() {
-			time.Sleep(1 * time.Second)
-			r.Action <- &ActionMessage{Type: "BOT_MOVE", PlayerID: firstPlayerID}
-		}()
+// cancelBotTurn abandons a pending bot turn, if any. Must be called with r.mu
+// held.
+func (r *Room) cancelBotTurn() {
+	if r.botCancel == nil {
+		return
 	}
+	r.botCancel()
+	r.botCancel = nil
+	r.broadcastEventInternal("BOT_TYPING", map[string]interface{}{"playerId": r.botThinking, "typing": false})
+	r.botThinking = ""
 }
 
-func (r *Room) processBotTurn(playerID string) {
+// applyBotAnswer plays a move decided by scheduleBotTurn.
+func (r *Room) applyBotAnswer(playerID string, move PlaceInfo) {
 	r.mu.Lock()
-	if r.State != StatePlaying {
-		r.mu.Unlock()
-		return
-	}
-	currentPlayerID := r.TurnOrder[r.CurrentTurnIndex]
-	if currentPlayerID != playerID {
+	if p, ok := r.Players[playerID]; !ok || p.Type != PlayerBot || r.botThinking != playerID {
 		r.mu.Unlock()
 		return
 	}
-
-	lastWord := r.LastWord
-	used := make(map[string]bool)
-	for k, v := range r.UsedWords {
-		used[k] = v
-	}
-	r.mu.Unlock()
-
-	log.Printf("[Bot] Thinking for last word: %s", lastWord)
-	move := r.BotBrain.GetMove(lastWord, used)
-	log.Printf("[Bot] Decided: %s", move.Name)
+	r.cancelBotTurn()
 
 	if move.Name == "" {
 		// Bot gives up or failed
-		r.mu.Lock()
-		player := r.Players[playerID]
-		player.Lives--
-		log.Printf("[Bot] Failed/Gave up, lives left: %d", player.Lives)
-		r.nextTurn()
-		
-		if r.checkGameOver() {
-			r.broadcastStateInternal()
-			r.mu.Unlock()
+		defer r.mu.Unlock()
+		// The turn may have timed out while the bot was thinking
+		if r.State != StatePlaying || r.TurnOrder[r.CurrentTurnIndex] != playerID {
 			return
 		}
-
-		r.broadcastStateInternal()
-
-		// Trigger NEXT bot if applicable
-		nextPlayerID := r.TurnOrder[r.CurrentTurnIndex]
-		if r.Players[nextPlayerID].Type == PlayerBot {
-			go func() {
-				time.Sleep(2 * time.Second)
-				r.Action <- &ActionMessage{Type: "BOT_MOVE", PlayerID: nextPlayerID}
-			}()
-		}
-		r.mu.Unlock()
-	} else {
-		r.processTurn(playerID, move.Name)
+		r.apply(TurnForfeited{PlayerID: playerID})
+		log.Printf("[Bot] Failed/Gave up, lives left: %d", r.Players[playerID].Lives)
+		return
 	}
+	r.mu.Unlock()
+	r.processTurn(playerID, move.Name, move.Type)
 }
 
-func (r *Room) processTurn(playerID string, word string) {
+// processTurn plays word for playerID. placeType optionally says which of
+// several same-named places was meant ("Georgia" the State); when empty the
+// highest-tier entry is used.
+func (r *Room) processTurn(playerID string, word string, placeType string) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -363,58 +501,54 @@ func (r *Room) processTurn(playerID string, word string) {
 	}
 
 	word = strings.TrimSpace(word)
-	lowerWord := strings.ToLower(word)
 	player := r.Players[playerID]
 
-	handleFailure := func(msg string) {
-		player.Lives--
-		if r.Mode == "SUDDEN_DEATH" {
-			player.Lives = 0
-		}
-		r.sendError(playerID, msg)
-		r.nextTurn()
-		
-		if r.checkGameOver() {
-			r.broadcastStateInternal()
-			return
+	// Validate
+	correctedFrom := ""
+	entries := r.Dict.Lookup(word)
+	if len(entries) == 0 {
+		if corrected, ok := r.autoCorrect(word); ok {
+			correctedFrom = word
+			word = corrected.Name
+			entries = r.Dict.Lookup(word)
 		}
-
-		r.broadcastStateInternal()
-
-		// Trigger next bot if it's their turn now
-		nextPlayerID := r.TurnOrder[r.CurrentTurnIndex]
-		if r.Players[nextPlayerID].Type == PlayerBot {
-			go func() {
-				time.Sleep(2 * time.Second)
-				r.Action <- &ActionMessage{Type: "BOT_MOVE", PlayerID: nextPlayerID}
-			}()
+	}
+	if len(entries) == 0 {
+		var details map[string]interface{}
+		if n := r.Settings["suggestions"]; n > 0 {
+			details = map[string]interface{}{"suggestions": r.Dict.SuggestIn(word, n, r.UsedWords, r.Filter)}
 		}
+		r.rejectWord(playerID, word, "Invalid place name!", details)
+		return
 	}
-
-	// Validate
-	isValid, pType, canonicalName := r.Dict.IsValid(word)
-	if !isValid {
-		handleFailure("Invalid place name!")
+	allowed := r.Filter.Apply(entries)
+	if len(allowed) == 0 {
+		r.rejectWord(playerID, word, fmt.Sprintf("%s doesn't count in this game (allowed: %s)!", entries[0].Name, r.Filter), nil)
 		return
 	}
-	if r.UsedWords[lowerWord] {
-		handleFailure("Place already used!")
+	place, ok := SelectType(allowed, placeType)
+	if !ok {
+		r.rejectWord(playerID, word, fmt.Sprintf("%s is not a %s!", entries[0].Name, placeType), nil)
 		return
 	}
+	pType, canonicalName := place.Type, place.Name
+	// Track the normalized canonical name so neither an alias nor a
+	// different accent or spelling can replay a used place
+	usedKey := NormalizePlace(canonicalName)
+	if r.UsedWords[usedKey] {
+		r.rejectWord(playerID, word, "Place already used!", nil)
+		return
+	}
+	// The chain runs on canonical names, so an alias can't dodge the letter
 	if r.LastWord != "" {
-		lastChar := strings.ToLower(string(r.LastWord[len(r.LastWord)-1]))
-		firstChar := strings.ToLower(string(word[0]))
-		if lastChar != firstChar {
-			handleFailure(fmt.Sprintf("Must start with '%s'!", strings.ToUpper(lastChar)))
+		lastChar := LastLetter(r.LastWord)
+		if FirstLetter(canonicalName) != lastChar {
+			r.rejectWord(playerID, word, fmt.Sprintf("Must start with '%s'!", strings.ToUpper(string(lastChar))), nil)
 			return
 		}
 	}
 
-	// Record Move
-	r.UsedWords[lowerWord] = true
-	r.LastWord = canonicalName
-	player.IsTurn = false
-	player.MostUsedPlaces[lowerWord]++
+	player.MostUsedPlaces[strings.ToLower(canonicalName)]++
 	
 	// Scoring Logic
 	points := 10
@@ -423,95 +557,278 @@ func (r *Room) processTurn(playerID string, word string) {
 		if seconds < 1 {
 			seconds = 1
 		}
-		points = int(100.0/seconds) + (len(word) * 5)
+		// Length bonus on the canonical name, so padding the input or
+		// typing an alias can't inflate it
+		points = int(100.0/seconds) + (utf8.RuneCountInString(canonicalName) * 5)
+	}
+
+	move := Move{
+		PlayerID:      playerID,
+		PlayerName:    player.Name,
+		Word:          canonicalName,
+		Type:          pType,
+		Timestamp:     time.Now().Unix(),
+		CorrectedFrom: correctedFrom,
+		CountryCode:   place.CountryCode,
+		Admin:         place.Admin,
+		Lat:           place.Lat,
+		Lon:           place.Lon,
+		Population:    place.Population,
+	}
+	r.apply(WordAccepted{PlayerID: playerID, Move: move, Points: points})
+}
+
+// rejectWord tells a player why their word didn't stand and charges them
+// the turn. Must be called with r.mu held.
+func (r *Room) rejectWord(playerID, word, msg string, details map[string]interface{}) {
+	r.sendErrorWithDetails(playerID, msg, details)
+	r.apply(WordRejected{PlayerID: playerID, Word: word, Reason: msg})
+}
+
+// autoCorrect returns the place an invalid word was most likely meant to be,
+// if the room allows auto-correction and exactly one place still playable in
+// this game is close enough.
+func (r *Room) autoCorrect(word string) (Suggestion, bool) {
+	threshold := r.Settings["autoCorrect"]
+	if threshold <= 0 {
+		return Suggestion{}, false
+	}
+	candidates := r.Dict.SuggestIn(word, 2, r.UsedWords, r.Filter)
+	if len(candidates) == 0 || candidates[0].Distance > threshold {
+		return Suggestion{}, false
+	}
+	if len(candidates) > 1 && candidates[1].Distance == candidates[0].Distance {
+		return Suggestion{}, false // Ambiguous; don't guess
+	}
+	return candidates[0], true
+}
+
+// handleTurnTimeout is run by the room loop when the current turn's deadline
+// passes without a move.
+func (r *Room) handleTurnTimeout() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.turnTimer = nil
+
+	if r.State != StatePlaying || len(r.TurnOrder) == 0 {
+		return
 	}
-	player.Score += points
 
-	r.History = append(r.History, Move{
-		PlayerID:   playerID,
-		PlayerName: player.Name,
-		Word:       canonicalName,
-		Type:       pType,
-		Timestamp:  time.Now().Unix(),
+	playerID := r.TurnOrder[r.CurrentTurnIndex]
+	player := r.Players[playerID]
+	log.Printf("[Room %s] Turn timed out for %s", r.ID, player.Name)
+
+	r.broadcastEventInternal("TURN_TIMEOUT", map[string]interface{}{
+		"playerId":   playerID,
+		"playerName": player.Name,
 	})
+	r.sendError(playerID, "Time's up!")
+	r.apply(TurnTimedOut{PlayerID: playerID})
+}
 
-	r.nextTurn()
-	r.broadcastStateInternal()
+// turnDuration returns the per-turn time limit for the current game.
+func (r *Room) turnDuration() time.Duration {
+	if secs := r.Settings["turnSeconds"]; secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	return DefaultTurnSeconds * time.Second
+}
 
-	if r.State == StateEnded {
+// resetTurnTimer starts a fresh deadline for whoever holds the turn, or clears
+// it if the game is not running. Must be called with r.mu held.
+func (r *Room) resetTurnTimer() {
+	r.stopTurnTimer()
+	if r.State != StatePlaying {
 		return
 	}
+	r.armTurnTimer(r.turnDuration())
+}
 
-	nextPlayerID := r.TurnOrder[r.CurrentTurnIndex]
-	if r.Players[nextPlayerID].Type == PlayerBot {
-		go func() {
-			time.Sleep(2 * time.Second)
-			r.Action <- &ActionMessage{Type: "BOT_MOVE", PlayerID: nextPlayerID}
-		}()
+// armTurnTimer ends the current turn d from now. Must be called with r.mu
+// held and no turn timer running.
+func (r *Room) armTurnTimer(d time.Duration) {
+	r.TurnDeadline = time.Now().Add(d)
+	r.turnTimer = time.NewTimer(d)
+}
+
+func (r *Room) stopTurnTimer() {
+	if r.turnTimer != nil {
+		r.turnTimer.Stop()
+		r.turnTimer = nil
 	}
+	r.TurnDeadline = time.Time{}
 }
 
-func (r *Room) nextTurn() {
-	player := r.Players[r.TurnOrder[r.CurrentTurnIndex]]
-	player.IsTurn = false
-	r.TurnStartTime = time.Now()
+// turnTimeout returns the channel the room loop waits on for the current
+// turn's deadline; nil (never ready) when no turn is being timed.
+func (r *Room) turnTimeout() <-chan time.Time {
+	if r.turnTimer == nil {
+		return nil
+	}
+	return r.turnTimer.C
+}
 
-	// Find next player with lives
-	for i := 0; i < len(r.TurnOrder); i++ {
-		r.CurrentTurnIndex = (r.CurrentTurnIndex + 1) % len(r.TurnOrder)
-		if r.CurrentTurnIndex == 0 {
-			r.Round++
-		}
-		nextPlayerID := r.TurnOrder[r.CurrentTurnIndex]
-		if r.Players[nextPlayerID].Lives > 0 {
-			r.Players[nextPlayerID].IsTurn = true
-			return
+// gameDuration returns the whole-game time limit, or 0 if the game is untimed.
+// POINT_RUSH is always timed; other modes only when "timeLimit" is set.
+func (r *Room) gameDuration() time.Duration {
+	if secs := r.Settings["timeLimit"]; secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if r.Mode == "POINT_RUSH" {
+		return DefaultPointRushSeconds * time.Second
+	}
+	return 0
+}
+
+// startGameClock arms the whole-game timer for a freshly started game.
+// Must be called with r.mu held.
+func (r *Room) startGameClock() {
+	r.stopGameClock()
+	d := r.gameDuration()
+	if d == 0 {
+		return
+	}
+	r.GameDeadline = time.Now().Add(d)
+	r.gameTimer = time.NewTimer(d)
+}
+
+func (r *Room) stopGameClock() {
+	if r.gameTimer != nil {
+		r.gameTimer.Stop()
+		r.gameTimer = nil
+	}
+	r.GameDeadline = time.Time{}
+}
+
+func (r *Room) gameTimeout() <-chan time.Time {
+	if r.gameTimer == nil {
+		return nil
+	}
+	return r.gameTimer.C
+}
+
+// handleGameTimeout ends a timed game when its clock runs out. The highest
+// score wins; a tie for first place is recorded as a draw.
+func (r *Room) handleGameTimeout() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gameTimer = nil
+
+	if r.State == StatePlaying {
+		log.Printf("[Room %s] Time limit reached", r.ID)
+	}
+	r.apply(GameTimedOut{})
+}
+
+// rules returns the room's game as the rules see it. Must be called with
+// r.mu held.
+func (r *Room) rules() Game {
+	g := Game{
+		Mode:      r.Mode,
+		State:     r.State,
+		Order:     r.TurnOrder,
+		Current:   r.CurrentTurnIndex,
+		Round:     r.Round,
+		Turn:      r.turn,
+		Seats:     make(map[string]Seat, len(r.Players)),
+		UsedWords: r.UsedWords,
+		LastWord:  r.LastWord,
+		History:   r.History,
+	}
+	for id, p := range r.Players {
+		g.Seats[id] = Seat{Score: p.Score, Lives: p.Lives}
+	}
+	return g
+}
+
+// setRules copies g back onto the room and its players. Players without a
+// seat in g have left. Must be called with r.mu held.
+func (r *Room) setRules(g Game) {
+	r.State = g.State
+	r.TurnOrder = g.Order
+	r.CurrentTurnIndex = g.Current
+	r.Round = g.Round
+	r.turn = g.Turn
+	r.UsedWords = g.UsedWords
+	r.LastWord = g.LastWord
+	r.History = g.History
+	for id, p := range r.Players {
+		seat, ok := g.Seats[id]
+		if !ok {
+			delete(r.Players, id)
+			continue
 		}
+		p.Score, p.Lives = seat.Score, seat.Lives
+		p.IsTurn = false
+	}
+	if id, ok := g.CurrentPlayer(); ok {
+		r.Players[id].IsTurn = true
 	}
-	// If we get here, no one has lives
-	r.State = StateEnded
-	r.checkGameOver() // Ensure stats are saved
 }
 
-func (r *Room) checkGameOver() bool {
-	alivePlayers := 0
-	var winnerName string
-	var winnerID string
-	
-	for _, p := range r.Players {
-		if p.Lives > 0 {
-			alivePlayers++
-			winnerName = p.Name
-			winnerID = p.ID
+// apply runs ev through the game rules and then does whatever the outcome
+// calls for: timing the new turn, waking a bot, saving stats and the replay
+// when the game ends, and broadcasting the new state. It reports false, doing
+// nothing, if ev doesn't apply to the game as it stands. Must be called with
+// r.mu held.
+func (r *Room) apply(ev GameEvent) bool {
+	before := r.rules()
+	g, ok := Apply(before, ev)
+	if !ok {
+		return false
+	}
+
+	// Recorded before the state changes, while a leaving player's name is
+	// still at hand
+	if _, started := ev.(GameStarted); started {
+		r.startReplay()
+	} else if rev, ok := r.replayEvent(ev); ok {
+		r.record(rev)
+	}
+	r.setRules(g)
+
+	newTurn := g.State == StatePlaying && g.Turn != before.Turn
+	switch {
+	case g.State == StatePlaying:
+		if _, started := ev.(GameStarted); started {
+			r.startGameClock()
+		}
+		if newTurn {
+			r.cancelBotTurn()
+			r.TurnStartTime = time.Now()
+			r.resetTurnTimer()
+		}
+	case before.State == StatePlaying:
+		r.stopTurnTimer()
+		r.stopGameClock()
+		r.cancelBotTurn()
+		if g.State == StateEnded {
+			log.Printf("[Room %s] Game over, winner: %q", r.ID, g.WinnerID)
+			r.saveStats(g.WinnerID)
+			r.finishReplay(g.WinnerID)
+		} else {
+			r.replay = nil // Abandoned, not finished
 		}
 	}
 
-	isGameOver := false
-	if alivePlayers <= 1 && len(r.Players) > 1 {
-		r.State = StateEnded
-		log.Printf("Game Over! Winner: %s", winnerName)
-		isGameOver = true
-	} else if alivePlayers == 0 && len(r.Players) == 1 {
-		r.State = StateEnded
-		log.Printf("Game Over! Solo player out of lives.")
-		winnerID = "" // No winner
-		isGameOver = true
-	} else if len(r.Players) == 0 {
-		r.State = StateEnded
-		return true
-	}
-
-	if isGameOver && r.UserManager != nil {
-		// Save Stats
-		for _, p := range r.Players {
-			if p.Type == PlayerHuman {
-				isWin := (p.ID == winnerID)
-				r.UserManager.UpdateStats(p.Name, p.Score, isWin)
-			}
+	r.broadcastStateInternal()
+	if newTurn {
+		r.scheduleBotTurn()
+	}
+	return true
+}
+
+// saveStats records the finished game for every human player.
+func (r *Room) saveStats(winnerID string) {
+	if r.UserManager == nil {
+		return
+	}
+	for _, p := range r.Players {
+		if p.Type == PlayerHuman {
+			isWin := (p.ID == winnerID)
+			r.UserManager.UpdateStats(p.Name, p.Score, isWin)
 		}
 	}
-	
-	return isGameOver
 }
 
 func (r *Room) broadcastState() {
@@ -526,41 +843,111 @@ func (r *Room) broadcastStateInternal() {
 		currentTurn = r.TurnOrder[r.CurrentTurnIndex]
 	}
 
+	// Deadline is sent both as an absolute time and as time remaining so
+	// clients with a skewed clock can still render an accurate countdown.
+	var turnDeadline, turnTimeRemaining int64
+	if !r.TurnDeadline.IsZero() {
+		turnDeadline = r.TurnDeadline.UnixMilli()
+		turnTimeRemaining = max(time.Until(r.TurnDeadline).Milliseconds(), 0)
+	}
+	var gameDeadline, timeRemaining int64
+	if !r.GameDeadline.IsZero() {
+		gameDeadline = r.GameDeadline.UnixMilli()
+		timeRemaining = max(time.Until(r.GameDeadline).Milliseconds(), 0)
+	}
+
 	state := map[string]interface{}{
 		"type": "GAME_STATE",
 		"payload": map[string]interface{}{
-			"players":     r.Players,
-			"state":       r.State,
-			"lastWord":    r.LastWord,
-			"turnOrder":   r.TurnOrder,
-			"currentTurn": currentTurn,
-			"history":     r.History,
-			"round":       r.Round,
+			"players":           r.Players,
+			"spectators":        r.Spectators,
+			"state":             r.State,
+			"lastWord":          r.LastWord,
+			"turnOrder":         r.TurnOrder,
+			"currentTurn":       currentTurn,
+			"history":           r.History,
+			"round":             r.Round,
+			"pack":              r.Pack,
+			"filter":            r.Filter,
+			"lateJoin":          r.LateJoin,
+			"hostId":            r.HostID,
+			"replayId":          r.lastReplayID,
+			"options":           r.Options,
+			"hasPasscode":       r.Options.Passcode != "",
+			"mode":              r.Mode,
+			"settings":          r.Settings,
+			"queued":            append([]string{}, r.lateJoiners...),
+			"turnSeconds":       int(r.turnDuration().Seconds()),
+			"turnDeadline":      turnDeadline,
+			"turnTimeRemaining": turnTimeRemaining,
+			"gameDeadline":      gameDeadline,
+			"timeRemaining":     timeRemaining,
 		},
 	}
 	bytes, _ := json.Marshal(state)
+	r.sendAllInternal(bytes)
+}
 
-	for _, player := range r.Players {
-		if player.Type == PlayerHuman {
-			select {
-			case player.Send <- bytes:
-			default:
-				// Avoid blocking if channel is full
+// sendAllInternal queues a message for every human and spectator without ever
+// blocking. Must be called with r.mu held.
+func (r *Room) sendAllInternal(bytes []byte) {
+	for _, group := range []map[string]*Player{r.Players, r.Spectators} {
+		for _, player := range group {
+			if player.Type == PlayerHuman {
+				select {
+				case player.Send <- bytes:
+				default:
+					// Avoid blocking if channel is full
+				}
 			}
 		}
 	}
 }
 
+// broadcastEventInternal sends a one-off event to every human in the room.
+// Must be called with r.mu held.
+func (r *Room) broadcastEventInternal(eventType string, payload interface{}) {
+	bytes, _ := json.Marshal(map[string]interface{}{
+		"type":    eventType,
+		"payload": payload,
+	})
+	r.sendAllInternal(bytes)
+}
+
+// sendTo queues a message for one connection without blocking.
+func sendTo(p *Player, msgType string, payload interface{}) {
+	bytes, _ := json.Marshal(map[string]interface{}{
+		"type":    msgType,
+		"payload": payload,
+	})
+	select {
+	case p.Send <- bytes:
+	default:
+	}
+}
+
 func (r *Room) sendError(playerID string, msg string) {
-	p, ok := r.Players[playerID]
+	r.sendErrorWithDetails(playerID, msg, nil)
+}
+
+func (r *Room) sendErrorWithDetails(playerID string, msg string, details map[string]interface{}) {
+	p, ok := r.member(playerID)
 	if !ok || p.Type != PlayerHuman {
 		return
 	}
 
+	payload := map[string]interface{}{"message": msg}
+	for k, v := range details {
+		payload[k] = v
+	}
 	errData := map[string]interface{}{
 		"type":    "ERROR",
-		"payload": map[string]string{"message": msg},
+		"payload": payload,
 	}
 	bytes, _ := json.Marshal(errData)
-	p.Send <- bytes
+	select {
+	case p.Send <- bytes:
+	default:
+		// Player is away or not keeping up; don't stall the room
+	}
 }
\ No newline at end of file
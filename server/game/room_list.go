@@ -0,0 +1,82 @@
+package game
+
+import (
+	"sort"
+)
+
+// RoomSummary is what the room browser shows about a room.
+type RoomSummary struct {
+	ID          string    `json:"id"`
+	State       GameState `json:"state"`
+	Mode        string    `json:"mode"`
+	Players     int       `json:"players"` // Bots included
+	Bots        int       `json:"bots"`
+	Spectators  int       `json:"spectators"`
+	MaxPlayers  int       `json:"maxPlayers"`
+	Host        string    `json:"host"` // Host's display name
+	Private     bool      `json:"private"`
+	HasPasscode bool      `json:"hasPasscode"`
+	AllowBots   bool      `json:"allowBots"`
+}
+
+// Summary snapshots the room for listing.
+func (r *Room) Summary() RoomSummary {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s := RoomSummary{
+		ID:          r.ID,
+		State:       r.State,
+		Mode:        r.Mode,
+		Players:     len(r.Players),
+		Spectators:  len(r.Spectators),
+		MaxPlayers:  r.Options.MaxPlayers,
+		Private:     r.Options.Private,
+		HasPasscode: r.Options.Passcode != "",
+		AllowBots:   r.Options.AllowBots,
+	}
+	for _, p := range r.Players {
+		if p.Type == PlayerBot {
+			s.Bots++
+		}
+	}
+	if host, ok := r.member(r.HostID); ok {
+		s.Host = host.Name
+	}
+	return s
+}
+
+// ListRooms returns every public room, those waiting for players first.
+func (m *Manager) ListRooms() []RoomSummary {
+	m.mu.RLock()
+	rooms := make([]*Room, 0, len(m.rooms))
+	for _, room := range m.rooms {
+		if !room.closed() {
+			rooms = append(rooms, room)
+		}
+	}
+	m.mu.RUnlock()
+
+	list := []RoomSummary{}
+	for _, room := range rooms {
+		if s := room.Summary(); !s.Private {
+			list = append(list, s)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if (list[i].State == StateWaiting) != (list[j].State == StateWaiting) {
+			return list[i].State == StateWaiting
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// RoomSummary looks up one room by ID. Private rooms are found too: knowing
+// the ID is what lets someone join them.
+func (m *Manager) RoomSummary(id string) (RoomSummary, bool) {
+	room, ok := m.room(id)
+	if !ok {
+		return RoomSummary{}, false
+	}
+	return room.Summary(), true
+}
@@ -0,0 +1,112 @@
+package game
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const (
+	DefaultMaxPlayers = 8
+	MaxPlayersLimit   = 16
+)
+
+// roomIDAlphabet leaves out look-alike characters so IDs can be read out
+// loud; 8 of them give roughly 40 bits, too many to guess.
+const roomIDAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+const roomIDLength = 8
+
+// RoomOptions are fixed when a room is created by the first connection to
+// it.
+type RoomOptions struct {
+	Private    bool   `json:"private"`    // Hidden from room listings
+	Passcode   string `json:"-"`          // Required to join when set
+	MaxPlayers int    `json:"maxPlayers"` // Seats, bots included; spectators don't count
+	AllowBots  bool   `json:"allowBots"`
+}
+
+// DefaultRoomOptions is a public room anyone can join, with bots allowed.
+func DefaultRoomOptions() RoomOptions {
+	return RoomOptions{MaxPlayers: DefaultMaxPlayers, AllowBots: true}
+}
+
+// ParseRoomOptions reads creation options from a /ws query: private,
+// passcode, maxPlayers and bots. Anything missing keeps its default.
+func ParseRoomOptions(q url.Values) (RoomOptions, error) {
+	opts := DefaultRoomOptions()
+	opts.Private = queryBool(q.Get("private"))
+	opts.Passcode = q.Get("passcode")
+	if v := q.Get("maxPlayers"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 2 || n > MaxPlayersLimit {
+			return opts, fmt.Errorf("maxPlayers must be between 2 and %d", MaxPlayersLimit)
+		}
+		opts.MaxPlayers = n
+	}
+	if v := q.Get("bots"); v != "" {
+		opts.AllowBots = queryBool(v)
+	}
+	return opts, nil
+}
+
+func queryBool(v string) bool {
+	return v == "1" || v == "true"
+}
+
+// newRoomID returns a random room code.
+func newRoomID() string {
+	b := make([]byte, roomIDLength)
+	rand.Read(b)
+	for i := range b {
+		b[i] = roomIDAlphabet[int(b[i])%len(roomIDAlphabet)]
+	}
+	return string(b)
+}
+
+// JoinError explains why a connection may not join a room. Status is the
+// HTTP code it's reported with before the websocket upgrade.
+type JoinError struct {
+	Status int
+	Reason string
+}
+
+func (e *JoinError) Error() string {
+	return e.Reason
+}
+
+var (
+	errWrongPasscode  = &JoinError{http.StatusForbidden, "Wrong or missing passcode for this room"}
+	errRoomFull       = &JoinError{http.StatusConflict, "This room is full"}
+	errGameInProgress = &JoinError{http.StatusConflict, "A game is already in progress. Try again when it ends."}
+)
+
+// CheckJoin reports whether a newcomer with passcode may join, as a player
+// or as a spectator.
+func (r *Room) CheckJoin(passcode string, spectate bool) *JoinError {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.Options.Passcode != "" && subtle.ConstantTimeCompare([]byte(passcode), []byte(r.Options.Passcode)) != 1 {
+		return errWrongPasscode
+	}
+	if spectate {
+		return nil
+	}
+	if r.State == StatePlaying && r.LateJoin == LateJoinReject {
+		return errGameInProgress
+	}
+	takesSeat := r.State != StatePlaying || r.LateJoin == LateJoinQueue
+	if takesSeat && r.seatsFull() {
+		return errRoomFull
+	}
+	return nil
+}
+
+// seatsFull reports whether every seat is taken, counting anyone queued for
+// the next game. Must be called with r.mu held.
+func (r *Room) seatsFull() bool {
+	return len(r.Players)+len(r.lateJoiners) >= r.Options.MaxPlayers
+}
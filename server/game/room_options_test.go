@@ -0,0 +1,106 @@
+package game
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// joinRejection makes a plain HTTP request to HandleWS and returns the reason
+// the join was refused before the upgrade, or "" if it got as far as
+// upgrading (which then fails, since this isn't a websocket client).
+func joinRejection(t *testing.T, m *Manager, query string) (int, string) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	m.HandleWS(w, httptest.NewRequest(http.MethodGet, "/ws?"+query, nil))
+	var body struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(w.Body.Bytes(), &body) != nil {
+		return w.Code, ""
+	}
+	return w.Code, body.Error
+}
+
+func TestJoinChecksBeforeUpgrade(t *testing.T) {
+	m := NewManager(nil, nil)
+	r, _ := testRoom(t, nil, "ann", "bob")
+	r.Options.Passcode = "sesame"
+	r.Options.MaxPlayers = 3
+	m.rooms["PRIV"] = r
+	full, _ := testRoom(t, nil, "cat", "dan")
+	full.Options.MaxPlayers = 2
+	m.rooms["FULL"] = full
+	playing, _ := startedRoom(t, "eve", "fay")
+	playing.LateJoin = LateJoinReject
+	m.rooms["BUSY"] = playing
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		reason string // Empty when the request got past the checks
+	}{
+		{"missing passcode", "room=PRIV", http.StatusForbidden, errWrongPasscode.Reason},
+		{"wrong passcode", "room=PRIV&passcode=open", http.StatusForbidden, errWrongPasscode.Reason},
+		{"wrong passcode to spectate", "room=PRIV&passcode=open&spectate=1", http.StatusForbidden, errWrongPasscode.Reason},
+		{"right passcode", "room=PRIV&passcode=sesame", http.StatusBadRequest, ""},
+		{"resume token instead of passcode", "room=PRIV&resume=token-ann", http.StatusBadRequest, ""},
+		{"unknown resume token", "room=PRIV&resume=nope", http.StatusForbidden, errWrongPasscode.Reason},
+		{"room full", "room=FULL", http.StatusConflict, errRoomFull.Reason},
+		{"spectating a full room", "room=FULL&spectate=1", http.StatusBadRequest, ""},
+		{"game in progress", "room=BUSY", http.StatusConflict, errGameInProgress.Reason},
+		{"bad player cap for a new room", "room=NEW&maxPlayers=40", http.StatusBadRequest, "maxPlayers must be between 2 and 16"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, reason := joinRejection(t, m, tt.query)
+			if status != tt.status || reason != tt.reason {
+				t.Errorf("got %d %q, want %d %q", status, reason, tt.status, tt.reason)
+			}
+		})
+	}
+}
+
+func TestCreateRoomReportsExisting(t *testing.T) {
+	m := snapshotManager(t, testDictionary(t, PlaceInfo{Name: "Oslo", Type: "City"}))
+	first := DefaultRoomOptions()
+	first.Passcode = "sesame"
+	room, created := m.createRoom("SAME", first)
+	if room == nil || !created {
+		t.Fatalf("createRoom(SAME) = %v, %v; want a new room", room, created)
+	}
+	t.Cleanup(func() { room.Close("test over") })
+
+	// A second connection racing to create the same ID must go through
+	// the first one's passcode, not set its own
+	again, created := m.createRoom("SAME", DefaultRoomOptions())
+	if again != room || created {
+		t.Fatalf("second createRoom(SAME) = %v, %v; want the existing room, not created", again, created)
+	}
+	if again.Options.Passcode != "sesame" {
+		t.Errorf("the second connection's options replaced the room's")
+	}
+	if jerr := again.CheckJoin("", false); jerr != errWrongPasscode {
+		t.Errorf("CheckJoin without the passcode = %v, want %v", jerr, errWrongPasscode)
+	}
+}
+
+func TestJoinAsPlayerRespectsCap(t *testing.T) {
+	r, _ := testRoom(t, nil, "ann", "bob")
+	r.Options.MaxPlayers = 2
+	watcher := NewPlayer("cat", "cat", PlayerHuman, nil)
+	watcher.Spectator = true
+	r.mu.Lock()
+	r.admit(watcher)
+	r.mu.Unlock()
+
+	r.setSpectator("cat", false)
+	if _, seated := r.Players["cat"]; seated {
+		t.Errorf("spectator took a seat in a full room")
+	}
+	if msg, ok := received(watcher, "ERROR"); !ok || msg["message"] != errRoomFull.Reason {
+		t.Errorf("spectator wasn't told the room is full: %v", msg)
+	}
+}
@@ -0,0 +1,45 @@
+package game
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPointRushScoring(t *testing.T) {
+	dict := testDictionary(t,
+		PlaceInfo{Name: "Lima", Type: "City"},
+		PlaceInfo{Name: "Łódź", Type: "City"},
+		PlaceInfo{Name: "Mumbai", Type: "City", Aliases: []string{"Bombay"}},
+		PlaceInfo{Name: "Ho Chi Minh City", Type: "City", Aliases: []string{"Saigon"}},
+	)
+
+	// Eight seconds into the turn is worth 12, plus 5 a letter
+	tests := []struct {
+		name  string
+		input string
+		want  int
+	}{
+		{"plain", "Lima", 32},
+		{"padded with punctuation", "L" + strings.Repeat("-", 500) + "ima", 32},
+		{"multi-byte letters", "Łódź", 32},
+		{"short alias", "Saigon", 12 + 16*5},
+		{"same-length alias", "Bombay", 42},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := testRoom(t, dict, "ann", "bob")
+			r.Mode = "POINT_RUSH"
+			r.startGame()
+			r.TurnStartTime = time.Now().Add(-8 * time.Second)
+
+			r.processTurn("ann", tt.input, "")
+			if len(r.History) != 1 {
+				t.Fatalf("%q wasn't accepted", tt.input)
+			}
+			if got := r.Players["ann"].Score; got != tt.want {
+				t.Errorf("%q scored %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
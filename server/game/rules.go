@@ -0,0 +1,281 @@
+package game
+
+import (
+	"maps"
+	"slices"
+)
+
+// Game is the state the rules care about. It is a value: Apply never
+// changes the Game it's given, so the same events always produce the same
+// game, whether they come from players or from a replay.
+type Game struct {
+	Mode      string
+	State     GameState
+	Order     []string // Turn order
+	Current   int      // Index into Order of whoever holds the turn
+	Round     int
+	Turn      int // Turns started so far; changes whenever the turn passes
+	Seats     map[string]Seat
+	UsedWords map[string]bool // NormalizePlace keys
+	LastWord  string
+	History   []Move
+	WinnerID  string // Set when the game ends; empty for no winner
+}
+
+// Seat is one player's standing in a game.
+type Seat struct {
+	Score int
+	Lives int
+}
+
+// GameEvent is something that happened in a game. Deciding whether a word
+// is valid needs the dictionary, so that is done before an event is made;
+// the events carry the verdict.
+type GameEvent interface {
+	gameEvent()
+}
+
+type GameStarted struct {
+	Mode  string
+	Order []string
+}
+
+type WordAccepted struct {
+	PlayerID string
+	Move     Move
+	Points   int
+}
+
+type WordRejected struct {
+	PlayerID string
+	Word     string
+	Reason   string
+}
+
+type TurnTimedOut struct {
+	PlayerID string
+}
+
+// TurnForfeited is a bot giving up on its turn.
+type TurnForfeited struct {
+	PlayerID string
+}
+
+type PlayerLeft struct {
+	PlayerID string
+}
+
+// GameTimedOut is the whole-game clock running out.
+type GameTimedOut struct{}
+
+// GameReset abandons any game and returns everyone to the lobby with fresh
+// scores and lives.
+type GameReset struct{}
+
+func (GameStarted) gameEvent()   {}
+func (WordAccepted) gameEvent()  {}
+func (WordRejected) gameEvent()  {}
+func (TurnTimedOut) gameEvent()  {}
+func (TurnForfeited) gameEvent() {}
+func (PlayerLeft) gameEvent()    {}
+func (GameTimedOut) gameEvent()  {}
+func (GameReset) gameEvent()     {}
+
+// startingLives is how many lives everyone gets in mode.
+func startingLives(mode string) int {
+	if mode == "SUDDEN_DEATH" {
+		return 1
+	}
+	return 3
+}
+
+// Apply returns the game after ev. It reports false, returning g unchanged,
+// when ev doesn't apply: GameStarted while a game is in play, a turn event
+// for someone whose turn it isn't, or any other event but PlayerLeft and
+// GameReset outside a game in play.
+func Apply(g Game, ev GameEvent) (Game, bool) {
+	switch e := ev.(type) {
+	case GameStarted:
+		if g.State == StatePlaying || len(e.Order) == 0 {
+			return g, false
+		}
+		n := Game{
+			Mode:      e.Mode,
+			State:     StatePlaying,
+			Order:     slices.Clone(e.Order),
+			Round:     1,
+			Turn:      g.Turn + 1,
+			Seats:     make(map[string]Seat, len(e.Order)),
+			UsedWords: make(map[string]bool),
+			History:   []Move{},
+		}
+		for _, id := range e.Order {
+			n.Seats[id] = Seat{Lives: startingLives(e.Mode)}
+		}
+		return n, true
+
+	case WordAccepted:
+		if !g.holdsTurn(e.PlayerID) {
+			return g, false
+		}
+		n := g.clone()
+		n.UsedWords[NormalizePlace(e.Move.Word)] = true
+		n.LastWord = e.Move.Word
+		n.History = append(n.History, e.Move)
+		seat := n.Seats[e.PlayerID]
+		seat.Score += e.Points
+		n.Seats[e.PlayerID] = seat
+		n.passTurn()
+		return n, true
+
+	case WordRejected:
+		return g.loseLife(e.PlayerID)
+	case TurnTimedOut:
+		return g.loseLife(e.PlayerID)
+	case TurnForfeited:
+		return g.loseLife(e.PlayerID)
+
+	case PlayerLeft:
+		return g.leave(e.PlayerID)
+
+	case GameTimedOut:
+		if g.State != StatePlaying {
+			return g, false
+		}
+		n := g.clone()
+		best := -1
+		for _, id := range n.Order {
+			if score := n.Seats[id].Score; score > best {
+				best, n.WinnerID = score, id
+			} else if score == best {
+				n.WinnerID = "" // A tie has no winner
+			}
+		}
+		n.State = StateEnded
+		return n, true
+
+	case GameReset:
+		n := Game{
+			Mode:      g.Mode,
+			State:     StateWaiting,
+			Order:     slices.Clone(g.Order),
+			Round:     1,
+			Turn:      g.Turn,
+			Seats:     make(map[string]Seat, len(g.Seats)),
+			UsedWords: make(map[string]bool),
+			History:   []Move{},
+		}
+		for id := range g.Seats {
+			n.Seats[id] = Seat{Lives: startingLives(g.Mode)}
+		}
+		return n, true
+	}
+	return g, false
+}
+
+// CurrentPlayer returns who holds the turn, if a game is in play.
+func (g Game) CurrentPlayer() (string, bool) {
+	if g.State != StatePlaying || g.Current >= len(g.Order) {
+		return "", false
+	}
+	return g.Order[g.Current], true
+}
+
+func (g Game) holdsTurn(playerID string) bool {
+	current, ok := g.CurrentPlayer()
+	return ok && current == playerID
+}
+
+func (g Game) clone() Game {
+	g.Order = slices.Clone(g.Order)
+	g.Seats = maps.Clone(g.Seats)
+	g.UsedWords = maps.Clone(g.UsedWords)
+	g.History = slices.Clone(g.History)
+	return g
+}
+
+// loseLife charges a life to the player holding the turn and passes play on.
+func (g Game) loseLife(playerID string) (Game, bool) {
+	if !g.holdsTurn(playerID) {
+		return g, false
+	}
+	n := g.clone()
+	seat := n.Seats[playerID]
+	seat.Lives--
+	if n.Mode == "SUDDEN_DEATH" {
+		seat.Lives = 0
+	}
+	n.Seats[playerID] = seat
+	n.passTurn()
+	n.checkOver()
+	return n, true
+}
+
+// leave takes a player out of the game. If they held the turn, it goes to
+// the next player still in.
+func (g Game) leave(playerID string) (Game, bool) {
+	idx := slices.Index(g.Order, playerID)
+	if idx < 0 {
+		return g, false
+	}
+	n := g.clone()
+	n.Order = slices.Delete(n.Order, idx, idx+1)
+	delete(n.Seats, playerID)
+
+	if len(n.Order) == 0 {
+		// Everyone's gone; the game is abandoned, not finished
+		n.State = StateWaiting
+		n.Current = 0
+		return n, true
+	}
+	if idx < n.Current {
+		n.Current--
+	} else if idx == n.Current {
+		n.Current = idx % len(n.Order)
+		if n.State == StatePlaying {
+			if n.Seats[n.Order[n.Current]].Lives > 0 {
+				n.Turn++
+			} else {
+				n.passTurn()
+			}
+		}
+	}
+	n.checkOver()
+	return n, true
+}
+
+// passTurn hands the turn to the next player with lives left, starting a new
+// round on wrapping past the first seat. With nobody left, the game ends.
+func (g *Game) passTurn() {
+	for range g.Order {
+		g.Current = (g.Current + 1) % len(g.Order)
+		if g.Current == 0 {
+			g.Round++
+		}
+		if g.Seats[g.Order[g.Current]].Lives > 0 {
+			g.Turn++
+			return
+		}
+	}
+	g.State = StateEnded
+	g.WinnerID = ""
+}
+
+// checkOver ends a game in play once at most one player is left standing,
+// or a lone player is out of lives.
+func (g *Game) checkOver() {
+	if g.State != StatePlaying {
+		return
+	}
+	alive, survivor := 0, ""
+	for _, id := range g.Order {
+		if g.Seats[id].Lives > 0 {
+			alive++
+			survivor = id
+		}
+	}
+	if (len(g.Order) > 1 && alive <= 1) || (len(g.Order) == 1 && alive == 0) {
+		g.State = StateEnded
+		g.WinnerID = survivor
+	}
+}
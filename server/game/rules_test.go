@@ -0,0 +1,292 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+// play starts a game for order in mode and applies events in turn, failing
+// the test if any of them doesn't apply.
+func play(t *testing.T, mode string, order []string, events ...GameEvent) Game {
+	t.Helper()
+	g, ok := Apply(Game{}, GameStarted{Mode: mode, Order: order})
+	if !ok {
+		t.Fatalf("GameStarted didn't apply")
+	}
+	for i, ev := range events {
+		if g, ok = Apply(g, ev); !ok {
+			t.Fatalf("event %d (%T) didn't apply", i, ev)
+		}
+	}
+	return g
+}
+
+func word(playerID, name string) WordAccepted {
+	return WordAccepted{PlayerID: playerID, Move: Move{PlayerID: playerID, Word: name}, Points: 10}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		order   []string
+		events  []GameEvent
+		state   GameState
+		current string // Who holds the turn, if still playing
+		winner  string
+		lives   map[string]int
+		round   int
+	}{
+		{
+			name:    "accepted word passes the turn",
+			mode:    "CLASSIC",
+			order:   []string{"a", "b"},
+			events:  []GameEvent{word("a", "Paris")},
+			state:   StatePlaying,
+			current: "b",
+			lives:   map[string]int{"a": 3, "b": 3},
+			round:   1,
+		},
+		{
+			name:    "timeout costs a life and starts a new round on wrap",
+			mode:    "CLASSIC",
+			order:   []string{"a", "b"},
+			events:  []GameEvent{word("a", "Paris"), TurnTimedOut{PlayerID: "b"}},
+			state:   StatePlaying,
+			current: "a",
+			lives:   map[string]int{"a": 3, "b": 2},
+			round:   2,
+		},
+		{
+			name:  "running out of lives ends the game",
+			mode:  "CLASSIC",
+			order: []string{"a", "b"},
+			events: []GameEvent{
+				word("a", "Paris"), TurnTimedOut{PlayerID: "b"},
+				word("a", "Sydney"), WordRejected{PlayerID: "b", Word: "Xyz", Reason: "Invalid place name!"},
+				word("a", "Oslo"), TurnForfeited{PlayerID: "b"},
+			},
+			state:  StateEnded,
+			winner: "a",
+			lives:  map[string]int{"a": 3, "b": 0},
+		},
+		{
+			name:   "sudden death takes every life at once",
+			mode:   "SUDDEN_DEATH",
+			order:  []string{"a", "b"},
+			events: []GameEvent{TurnTimedOut{PlayerID: "a"}},
+			state:  StateEnded,
+			winner: "b",
+			lives:  map[string]int{"a": 0, "b": 1},
+		},
+		{
+			name:    "sudden death skips players who are out",
+			mode:    "SUDDEN_DEATH",
+			order:   []string{"a", "b", "c"},
+			events:  []GameEvent{TurnTimedOut{PlayerID: "a"}, word("b", "Paris")},
+			state:   StatePlaying,
+			current: "c",
+			lives:   map[string]int{"a": 0, "b": 1, "c": 1},
+			round:   1,
+		},
+		{
+			name:    "current player leaving hands the turn to the next seat",
+			mode:    "CLASSIC",
+			order:   []string{"a", "b", "c"},
+			events:  []GameEvent{word("a", "Paris"), PlayerLeft{PlayerID: "b"}},
+			state:   StatePlaying,
+			current: "c",
+			lives:   map[string]int{"a": 3, "c": 3},
+			round:   1,
+		},
+		{
+			name:    "last seat leaving wraps the turn to the first",
+			mode:    "CLASSIC",
+			order:   []string{"a", "b", "c"},
+			events:  []GameEvent{word("a", "Paris"), word("b", "Sydney"), PlayerLeft{PlayerID: "c"}},
+			state:   StatePlaying,
+			current: "a",
+			lives:   map[string]int{"a": 3, "b": 3},
+			round:   1,
+		},
+		{
+			name:   "leaving down to one survivor ends the game",
+			mode:   "SUDDEN_DEATH",
+			order:  []string{"a", "b", "c"},
+			events: []GameEvent{TurnTimedOut{PlayerID: "a"}, PlayerLeft{PlayerID: "b"}},
+			state:  StateEnded,
+			winner: "c",
+			lives:  map[string]int{"a": 0, "c": 1},
+		},
+		{
+			name:    "a lone player keeps going while they have lives",
+			mode:    "CLASSIC",
+			order:   []string{"a", "b"},
+			events:  []GameEvent{PlayerLeft{PlayerID: "a"}},
+			state:   StatePlaying,
+			current: "b",
+			lives:   map[string]int{"b": 3},
+			round:   1,
+		},
+		{
+			name:   "everyone leaving abandons the game",
+			mode:   "CLASSIC",
+			order:  []string{"a", "b"},
+			events: []GameEvent{PlayerLeft{PlayerID: "a"}, PlayerLeft{PlayerID: "b"}},
+			state:  StateWaiting,
+			lives:  map[string]int{},
+		},
+		{
+			name:   "game clock gives the win to the top score",
+			mode:   "POINT_RUSH",
+			order:  []string{"a", "b"},
+			events: []GameEvent{word("a", "Paris"), GameTimedOut{}},
+			state:  StateEnded,
+			winner: "a",
+			lives:  map[string]int{"a": 3, "b": 3},
+		},
+		{
+			name:   "game clock with a tie has no winner",
+			mode:   "POINT_RUSH",
+			order:  []string{"a", "b"},
+			events: []GameEvent{word("a", "Paris"), word("b", "Sydney"), GameTimedOut{}},
+			state:  StateEnded,
+			winner: "",
+			lives:  map[string]int{"a": 3, "b": 3},
+		},
+		{
+			name:   "reset returns everyone to the lobby",
+			mode:   "SUDDEN_DEATH",
+			order:  []string{"a", "b"},
+			events: []GameEvent{word("a", "Paris"), GameReset{}},
+			state:  StateWaiting,
+			lives:  map[string]int{"a": 1, "b": 1},
+			round:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := play(t, tt.mode, tt.order, tt.events...)
+			if g.State != tt.state {
+				t.Errorf("state = %s, want %s", g.State, tt.state)
+			}
+			current, _ := g.CurrentPlayer()
+			if current != tt.current {
+				t.Errorf("current player = %q, want %q", current, tt.current)
+			}
+			if g.State == StateEnded && g.WinnerID != tt.winner {
+				t.Errorf("winner = %q, want %q", g.WinnerID, tt.winner)
+			}
+			lives := make(map[string]int, len(g.Seats))
+			for id, seat := range g.Seats {
+				lives[id] = seat.Lives
+			}
+			if !reflect.DeepEqual(lives, tt.lives) {
+				t.Errorf("lives = %v, want %v", lives, tt.lives)
+			}
+			if tt.round != 0 && g.Round != tt.round {
+				t.Errorf("round = %d, want %d", g.Round, tt.round)
+			}
+		})
+	}
+}
+
+func TestApplyRefuses(t *testing.T) {
+	g := play(t, "CLASSIC", []string{"a", "b"})
+	ended := play(t, "SUDDEN_DEATH", []string{"a", "b"}, TurnTimedOut{PlayerID: "a"})
+
+	tests := []struct {
+		name string
+		g    Game
+		ev   GameEvent
+	}{
+		{"word out of turn", g, word("b", "Paris")},
+		{"timeout for someone else", g, TurnTimedOut{PlayerID: "b"}},
+		{"restart mid-game", g, GameStarted{Mode: "SUDDEN_DEATH", Order: []string{"a", "b"}}},
+		{"unknown player leaving", g, PlayerLeft{PlayerID: "z"}},
+		{"word after the game ended", ended, word("b", "Paris")},
+		{"clock after the game ended", ended, GameTimedOut{}},
+		{"start with nobody", Game{}, GameStarted{Mode: "CLASSIC"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Apply(tt.g, tt.ev)
+			if ok {
+				t.Fatalf("%T applied", tt.ev)
+			}
+			if !reflect.DeepEqual(got, tt.g) {
+				t.Errorf("refused event changed the game")
+			}
+		})
+	}
+}
+
+func TestApplyLeavesInputAlone(t *testing.T) {
+	g := play(t, "CLASSIC", []string{"a", "b"})
+	next, _ := Apply(g, word("a", "Paris"))
+	next, _ = Apply(next, TurnTimedOut{PlayerID: "b"})
+	if len(g.History) != 0 || len(g.UsedWords) != 0 || g.Seats["a"].Score != 0 || g.Seats["b"].Lives != 3 {
+		t.Errorf("Apply changed the game it was given: %+v", g)
+	}
+	if len(next.History) != 1 || !next.UsedWords["paris"] || next.LastWord != "Paris" {
+		t.Errorf("unexpected result: %+v", next)
+	}
+}
+
+func TestReplayRebuild(t *testing.T) {
+	order := []string{"a", "b", "c"}
+	events := []GameEvent{
+		word("a", "Paris"),
+		WordRejected{PlayerID: "b", Word: "Xyz", Reason: "Invalid place name!"},
+		word("c", "Sydney"),
+		PlayerLeft{PlayerID: "a"},
+		TurnTimedOut{PlayerID: "b"},
+		word("c", "Yerevan"),
+		TurnForfeited{PlayerID: "b"},
+	}
+	want := play(t, "CLASSIC", order, events...)
+	if want.State != StateEnded || want.WinnerID != "c" {
+		t.Fatalf("game didn't end as expected: %+v", want)
+	}
+
+	// Record the game the way a room does
+	r := NewRoom("test", nil, nil)
+	for _, id := range order {
+		p := NewPlayer(id, id, PlayerHuman, nil)
+		r.Players[id] = p
+		r.TurnOrder = append(r.TurnOrder, id)
+	}
+	r.Mode = "CLASSIC"
+	r.startReplay()
+	for _, ev := range events {
+		rev, ok := r.replayEvent(ev)
+		if !ok {
+			t.Fatalf("%T has no replay event", ev)
+		}
+		r.record(rev)
+	}
+	r.record(ReplayEvent{Type: "GAME_ENDED", PlayerID: want.WinnerID})
+
+	got := r.replay.Rebuild()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Rebuild() = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestReplayRebuildGameClock(t *testing.T) {
+	rep := &Replay{
+		Mode:    "POINT_RUSH",
+		Players: []ReplayPlayer{{ID: "a"}, {ID: "b"}},
+		Events: []ReplayEvent{
+			{Type: "GAME_STARTED"},
+			{Type: "WORD_ACCEPTED", PlayerID: "a", Move: &Move{Word: "Oslo"}, Points: 40},
+			{Type: "CHAT", PlayerID: "b", Message: "nice"},
+			{Type: "GAME_ENDED", PlayerID: "a"},
+		},
+	}
+	g := rep.Rebuild()
+	if g.State != StateEnded || g.WinnerID != "a" || g.Seats["a"].Score != 40 {
+		t.Errorf("Rebuild() = %+v", g)
+	}
+}
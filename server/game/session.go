@@ -0,0 +1,119 @@
+package game
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// DefaultReconnectGrace is how long a dropped player's seat is held when the
+// room doesn't set Settings["reconnectSeconds"]. Their turn timer keeps
+// running meanwhile, so an absent player still loses lives.
+const DefaultReconnectGrace = 60 * time.Second
+
+// connEvent ties a player to one specific websocket, so events from a
+// connection that has since been replaced can be told apart and ignored.
+type connEvent struct {
+	player *Player
+	conn   *websocket.Conn
+	done   chan bool // Register and Reattach: reports whether the player got in
+}
+
+func (r *Room) reconnectGrace() time.Duration {
+	if secs := r.Settings["reconnectSeconds"]; secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	return DefaultReconnectGrace
+}
+
+// FindByResumeToken returns the player or spectator holding token in this
+// room, if any.
+func (r *Room) FindByResumeToken(token string) (*Player, bool) {
+	if token == "" {
+		return nil, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, group := range []map[string]*Player{r.Players, r.Spectators} {
+		for _, p := range group {
+			if p.Type == PlayerHuman && p.ResumeToken == token {
+				return p, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// handleDisconnect runs when a player's websocket drops. The first event for
+// a connection holds the seat for the grace period; if the same connection is
+// still attached when the grace event comes back, the player is removed.
+func (r *Room) handleDisconnect(ev connEvent) {
+	r.mu.Lock()
+	p, ok := r.member(ev.player.ID)
+	if !ok || p != ev.player || p.Conn != ev.conn {
+		// Already gone, or resumed on a newer connection
+		r.mu.Unlock()
+		return
+	}
+
+	if !p.Connected {
+		log.Printf("[Room %s] %s did not reconnect in time", r.ID, p.Name)
+		r.removePlayer(p)
+		r.mu.Unlock()
+		r.broadcastState()
+		return
+	}
+
+	p.Connected = false
+	// Stop the old write pump and buffer for the player while they're away;
+	// broadcasts never block on a full buffer.
+	close(p.Send)
+	p.Send = make(chan []byte, 256)
+	log.Printf("[Room %s] %s disconnected, holding seat for %s", r.ID, p.Name, r.reconnectGrace())
+	r.holdSeat(ev)
+	r.mu.Unlock()
+	r.broadcastState()
+}
+
+// holdSeat gives the player in ev the reconnect grace period to come back on
+// a new connection; if ev's connection is still theirs when it runs out,
+// they're removed. Must be called with r.mu held.
+func (r *Room) holdSeat(ev connEvent) {
+	time.AfterFunc(r.reconnectGrace(), func() {
+		deliver(r, r.Disconnect, ev)
+	})
+}
+
+// handleReattach moves a player onto a new websocket and resyncs them. If the
+// player is somehow still connected elsewhere, that older connection is
+// dropped.
+func (r *Room) handleReattach(ev connEvent) {
+	r.mu.Lock()
+	p, ok := r.member(ev.player.ID)
+	if !ok || p != ev.player {
+		r.mu.Unlock()
+		ev.done <- false
+		return
+	}
+
+	close(p.Send) // Drops whatever queued up while away; a full resync follows
+	if p.Connected && p.Conn != nil {
+		p.Conn.Close()
+	}
+	p.Conn = ev.conn
+	p.Send = make(chan []byte, 256)
+	p.Connected = true
+	log.Printf("[Room %s] %s reconnected", r.ID, p.Name)
+
+	history, _ := json.Marshal(map[string]interface{}{
+		"type":    "CHAT_HISTORY",
+		"payload": map[string]interface{}{"messages": r.ChatHistory},
+	})
+	p.Send <- history
+	r.broadcastStateInternal()
+	r.mu.Unlock()
+
+	ev.done <- true
+}
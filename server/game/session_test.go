@@ -0,0 +1,77 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// testRoom returns a room with the given humans seated, in order. It isn't
+// running; tests drive its handlers directly.
+func testRoom(t *testing.T, dict *Dictionary, names ...string) (*Room, []*Player) {
+	t.Helper()
+	r := NewRoom("test", dict, nil)
+	r.BotBrain = NewBot()
+	t.Cleanup(func() { r.Close("test over") })
+
+	var players []*Player
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, name := range names {
+		p := NewPlayer(name, name, PlayerHuman, &websocket.Conn{})
+		p.ResumeToken = "token-" + name
+		if !r.admit(p) {
+			t.Fatalf("%s wasn't admitted", name)
+		}
+		players = append(players, p)
+	}
+	return r, players
+}
+
+func TestResumeTokenExpiry(t *testing.T) {
+	r, players := testRoom(t, nil, "ann", "bob")
+	ann := players[0]
+	dropped := connEvent{player: ann, conn: ann.Conn}
+
+	r.handleDisconnect(dropped)
+	if ann.Connected {
+		t.Fatalf("ann is still connected after dropping")
+	}
+	if p, ok := r.FindByResumeToken("token-ann"); !ok || p != ann {
+		t.Fatalf("ann's seat isn't held during the grace period")
+	}
+
+	// The grace period runs out with the dropped connection still attached
+	r.handleDisconnect(dropped)
+	if _, ok := r.FindByResumeToken("token-ann"); ok {
+		t.Errorf("resume token still works after the grace period")
+	}
+	if _, ok := r.Players["ann"]; ok {
+		t.Errorf("ann still has a seat after the grace period")
+	}
+	if r.HostID != "bob" {
+		t.Errorf("host = %q, want bob once ann is gone", r.HostID)
+	}
+}
+
+func TestResumeWithinGrace(t *testing.T) {
+	r, players := testRoom(t, nil, "ann", "bob")
+	ann := players[0]
+	dropped := connEvent{player: ann, conn: ann.Conn}
+	r.handleDisconnect(dropped)
+
+	done := make(chan bool, 1)
+	r.handleReattach(connEvent{player: ann, conn: &websocket.Conn{}, done: done})
+	if !<-done || !ann.Connected {
+		t.Fatalf("ann couldn't resume their seat")
+	}
+
+	// The grace period ending later must not remove them
+	r.handleDisconnect(dropped)
+	if p, ok := r.FindByResumeToken("token-ann"); !ok || p != ann || !ann.Connected {
+		t.Errorf("a stale grace timeout removed a resumed player")
+	}
+	if _, ok := r.FindByResumeToken(""); ok {
+		t.Errorf("an empty token matched a player")
+	}
+}
@@ -0,0 +1,90 @@
+package game
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+)
+
+var errShuttingDown = &JoinError{http.StatusServiceUnavailable, "The server is restarting. Try again in a moment."}
+
+func (m *Manager) draining() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.isDraining
+}
+
+// announce sends an event to everyone in the room from outside Run.
+func (r *Room) announce(eventType string, payload interface{}) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	r.broadcastEventInternal(eventType, payload)
+}
+
+func (r *Room) playing() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.State == StatePlaying
+}
+
+// Shutdown prepares the manager for the process to exit. No new rooms are
+// created from here on. Every room is warned with SERVER_SHUTTING_DOWN and
+// given up to grace to finish its game. Then every room is stopped, and
+// games still running are saved to store (skipped when nil). User stats need
+// no saving; UpdateStats writes them as each game ends.
+func (m *Manager) Shutdown(grace time.Duration, store SnapshotStore) error {
+	m.mu.Lock()
+	m.isDraining = true
+	rooms := make([]*Room, 0, len(m.rooms))
+	for _, room := range m.rooms {
+		if !room.closed() {
+			rooms = append(rooms, room)
+		}
+	}
+	m.mu.Unlock()
+
+	deadline := time.Now().Add(grace)
+	log.Printf("[Shutdown] Draining %d rooms, %s grace", len(rooms), grace)
+	for _, room := range rooms {
+		room.announce("SERVER_SHUTTING_DOWN", map[string]interface{}{
+			"seconds":  int(grace.Seconds()),
+			"deadline": deadline.UnixMilli(),
+			"message":  "The server is restarting soon. Games still running will be saved.",
+		})
+	}
+
+	// Give games a chance to finish on their own
+	for time.Now().Before(deadline) && anyPlaying(rooms) {
+		time.Sleep(250 * time.Millisecond)
+	}
+
+	var errs []error
+	for _, room := range rooms {
+		// Once stopped the room can't change, so no move slips in between
+		// the snapshot and closing
+		room.Stop("Server is restarting")
+		if store == nil {
+			continue
+		}
+		s := room.Snapshot()
+		if s.State != StatePlaying {
+			continue
+		}
+		if err := store.Save(s); err != nil {
+			errs = append(errs, err)
+		} else {
+			log.Printf("[Shutdown] Saved room %s", room.ID)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func anyPlaying(rooms []*Room) bool {
+	for _, room := range rooms {
+		if room.playing() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+// memorySnapshots is a SnapshotStore kept in memory.
+type memorySnapshots map[string]RoomSnapshot
+
+func (ms memorySnapshots) Save(s RoomSnapshot) error {
+	ms[s.ID] = s
+	return nil
+}
+
+func (ms memorySnapshots) LoadAll() ([]RoomSnapshot, error) {
+	var snaps []RoomSnapshot
+	for _, s := range ms {
+		snaps = append(snaps, s)
+	}
+	return snaps, nil
+}
+
+func (ms memorySnapshots) Delete(roomID string) error {
+	delete(ms, roomID)
+	return nil
+}
+
+func TestShutdownSavesRunningGames(t *testing.T) {
+	m := NewManager(nil, nil)
+	playing, _ := startedRoom(t, "ann", "bob")
+	playing.ID = "PLAY"
+	lobby, _ := testRoom(t, nil, "cat", "dan")
+	lobby.ID = "LOBBY"
+	for _, r := range []*Room{playing, lobby} {
+		m.rooms[r.ID] = r
+		go r.Run()
+	}
+
+	store := memorySnapshots{}
+	if err := m.Shutdown(0, store); err != nil {
+		t.Fatalf("Shutdown() = %v", err)
+	}
+
+	for _, r := range []*Room{playing, lobby} {
+		select {
+		case <-r.stopped:
+		default:
+			t.Errorf("room %s is still running", r.ID)
+		}
+	}
+	if _, ok := store["LOBBY"]; ok {
+		t.Errorf("a room without a game in play was saved")
+	}
+	s, ok := store["PLAY"]
+	if !ok {
+		t.Fatalf("running game wasn't saved")
+	}
+	if s.State != StatePlaying || len(s.Players) != 2 {
+		t.Errorf("saved %s game with %d players", s.State, len(s.Players))
+	}
+	if s.TurnTimeRemainingMs <= 0 || s.TurnTimeRemainingMs > (DefaultTurnSeconds*time.Second).Milliseconds() {
+		t.Errorf("saved turn time remaining = %dms", s.TurnTimeRemainingMs)
+	}
+	if room, _ := m.createRoom("", DefaultRoomOptions()); room != nil {
+		t.Errorf("a room was created after shutdown")
+	}
+}
@@ -0,0 +1,268 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+)
+
+// SnapshotVersion is bumped whenever RoomSnapshot changes incompatibly.
+const SnapshotVersion = 1
+
+// RoomSnapshot is everything needed to bring a room back after a restart.
+// Timers are stored as time remaining, since the clock keeps running while
+// the server is down.
+type RoomSnapshot struct {
+	Version int       `json:"version"`
+	SavedAt time.Time `json:"savedAt"`
+
+	ID       string         `json:"id"`
+	State    GameState      `json:"state"`
+	Mode     string         `json:"mode"`
+	Settings map[string]int `json:"settings"`
+	Filter   PlaceFilter    `json:"filter"`
+	Pack     string         `json:"pack"`
+	LateJoin string         `json:"lateJoin"`
+	HostID   string         `json:"hostId"`
+	Options  RoomOptions    `json:"options"`
+	Passcode string         `json:"passcode,omitempty"` // RoomOptions keeps it out of JSON
+
+	Players          []PlayerSnapshot `json:"players"` // In turn order
+	Spectators       []PlayerSnapshot `json:"spectators"`
+	LateJoiners      []string         `json:"lateJoiners,omitempty"`
+	CurrentTurnIndex int              `json:"currentTurnIndex"`
+	UsedWords        []string         `json:"usedWords"`
+	LastWord         string           `json:"lastWord"`
+	History          []Move           `json:"history"`
+	Round            int              `json:"round"`
+	ChatHistory      []ChatMessage    `json:"chatHistory"`
+	Replay           *Replay          `json:"replay,omitempty"` // The game's recording so far
+
+	TurnTimeRemainingMs int64 `json:"turnTimeRemainingMs"`
+	GameTimeRemainingMs int64 `json:"gameTimeRemainingMs"` // Only meaningful for a timed game
+}
+
+// PlayerSnapshot is a Player without its connection, including the resume
+// token that lets them reclaim the seat.
+type PlayerSnapshot struct {
+	ID             string         `json:"id"`
+	Name           string         `json:"name"`
+	Type           PlayerType     `json:"type"`
+	Score          int            `json:"score"`
+	Lives          int            `json:"lives"`
+	IsTurn         bool           `json:"isTurn"`
+	AvatarURL      string         `json:"avatarUrl,omitempty"`
+	MostUsedPlaces map[string]int `json:"mostUsedPlaces,omitempty"`
+	BotLevel       BotDifficulty  `json:"botLevel,omitempty"`
+	ResumeToken    string         `json:"resumeToken,omitempty"`
+	Spectator      bool           `json:"spectator,omitempty"`
+}
+
+func snapshotPlayer(p *Player) PlayerSnapshot {
+	return PlayerSnapshot{
+		ID:             p.ID,
+		Name:           p.Name,
+		Type:           p.Type,
+		Score:          p.Score,
+		Lives:          p.Lives,
+		IsTurn:         p.IsTurn,
+		AvatarURL:      p.AvatarURL,
+		MostUsedPlaces: maps.Clone(p.MostUsedPlaces),
+		BotLevel:       p.BotLevel,
+		ResumeToken:    p.ResumeToken,
+		Spectator:      p.Spectator,
+	}
+}
+
+// Snapshot captures the room's current state. It shares nothing with the
+// room, so it can be saved after the lock is released while play goes on.
+func (r *Room) Snapshot() RoomSnapshot {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	s := RoomSnapshot{
+		Version:          SnapshotVersion,
+		SavedAt:          now,
+		ID:               r.ID,
+		State:            r.State,
+		Mode:             r.Mode,
+		Settings:         maps.Clone(r.Settings),
+		Filter:           r.Filter,
+		Pack:             r.Pack,
+		LateJoin:         r.LateJoin,
+		HostID:           r.HostID,
+		Options:          r.Options,
+		Passcode:         r.Options.Passcode,
+		LateJoiners:      slices.Clone(r.lateJoiners),
+		CurrentTurnIndex: r.CurrentTurnIndex,
+		LastWord:         r.LastWord,
+		History:          slices.Clone(r.History),
+		Round:            r.Round,
+		ChatHistory:      slices.Clone(r.ChatHistory),
+	}
+	if r.replay != nil {
+		rep := *r.replay
+		rep.Settings = maps.Clone(rep.Settings)
+		rep.Players = slices.Clone(rep.Players)
+		rep.Events = slices.Clone(rep.Events)
+		s.Replay = &rep
+	}
+	for _, id := range r.TurnOrder {
+		s.Players = append(s.Players, snapshotPlayer(r.Players[id]))
+	}
+	for _, p := range r.Spectators {
+		s.Spectators = append(s.Spectators, snapshotPlayer(p))
+	}
+	for key, used := range r.UsedWords {
+		if used {
+			s.UsedWords = append(s.UsedWords, key)
+		}
+	}
+	sort.Strings(s.UsedWords)
+	if !r.TurnDeadline.IsZero() {
+		s.TurnTimeRemainingMs = max(r.TurnDeadline.Sub(now).Milliseconds(), 0)
+	}
+	if !r.GameDeadline.IsZero() {
+		s.GameTimeRemainingMs = max(r.GameDeadline.Sub(now).Milliseconds(), 0)
+	}
+	return s
+}
+
+// restorePlayer rebuilds a player from s. Humans come back disconnected,
+// with a buffer to queue messages until they reattach.
+func restorePlayer(s PlayerSnapshot) *Player {
+	p := NewPlayer(s.ID, s.Name, s.Type, nil)
+	p.Score = s.Score
+	p.Lives = s.Lives
+	p.IsTurn = s.IsTurn
+	p.AvatarURL = s.AvatarURL
+	if s.MostUsedPlaces != nil {
+		p.MostUsedPlaces = s.MostUsedPlaces
+	}
+	p.BotLevel = s.BotLevel
+	p.ResumeToken = s.ResumeToken
+	p.Spectator = s.Spectator
+	p.Connected = s.Type != PlayerHuman
+	return p
+}
+
+// restoreRoom rebuilds a room from s without starting it. The turn and game
+// clocks pick up with the time they had left; a timed game that had none
+// left is over. Humans get the usual reconnect grace afterwards, from
+// RestoreRooms.
+func (m *Manager) restoreRoom(s RoomSnapshot) (*Room, error) {
+	if s.Version != SnapshotVersion {
+		return nil, fmt.Errorf("room %s: unsupported snapshot version %d", s.ID, s.Version)
+	}
+	dict, ok := m.packs.Get(s.Pack)
+	if !ok {
+		return nil, fmt.Errorf("room %s: unknown data pack '%s'", s.ID, s.Pack)
+	}
+
+	r := NewRoom(s.ID, dict, m.um)
+	r.Packs = m.packs
+	r.Replays = m.Replays
+	r.Pack = s.Pack
+	r.State = s.State
+	r.Mode = s.Mode
+	if s.Settings != nil {
+		r.Settings = s.Settings
+	}
+	r.Filter = s.Filter
+	r.LateJoin = s.LateJoin
+	r.HostID = s.HostID
+	r.Options = s.Options
+	r.Options.Passcode = s.Passcode
+	r.lateJoiners = s.LateJoiners
+	r.CurrentTurnIndex = s.CurrentTurnIndex
+	r.LastWord = s.LastWord
+	if s.History != nil {
+		r.History = s.History
+	}
+	r.Round = s.Round
+	if s.ChatHistory != nil {
+		r.ChatHistory = s.ChatHistory
+	}
+	if r.State == StatePlaying {
+		r.replay = s.Replay // Carries on so the finished game is still saved
+	}
+	for _, key := range s.UsedWords {
+		r.UsedWords[key] = true
+	}
+	for _, ps := range s.Players {
+		p := restorePlayer(ps)
+		r.Players[p.ID] = p
+		r.TurnOrder = append(r.TurnOrder, p.ID)
+	}
+	for _, ps := range s.Spectators {
+		p := restorePlayer(ps)
+		r.Spectators[p.ID] = p
+	}
+
+	if r.State == StatePlaying {
+		if len(r.TurnOrder) == 0 || r.CurrentTurnIndex >= len(r.TurnOrder) {
+			return nil, fmt.Errorf("room %s: snapshot has no valid current turn", s.ID)
+		}
+		turnLeft := time.Duration(s.TurnTimeRemainingMs) * time.Millisecond
+		r.TurnStartTime = time.Now().Add(turnLeft - r.turnDuration())
+		r.armTurnTimer(turnLeft)
+		if r.gameDuration() > 0 {
+			gameLeft := time.Duration(s.GameTimeRemainingMs) * time.Millisecond
+			if gameLeft <= 0 {
+				// The clock ran out as the server went down
+				r.mu.Lock()
+				r.apply(GameTimedOut{})
+				r.mu.Unlock()
+			} else {
+				r.GameDeadline = time.Now().Add(gameLeft)
+				r.gameTimer = time.NewTimer(gameLeft)
+			}
+		}
+	}
+	return r, nil
+}
+
+// RestoreRooms brings back every room saved in store and starts it, then
+// removes the snapshots so they're only restored once. Rooms that can't be
+// restored are skipped and reported in the returned error.
+func (m *Manager) RestoreRooms(store SnapshotStore) (int, error) {
+	// Whatever could be read is restored even if some snapshots couldn't
+	snaps, err := store.LoadAll()
+	errs := []error{err}
+	restored := 0
+	for _, s := range snaps {
+		room, err := m.restoreRoom(s)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		m.mu.Lock()
+		m.rooms[room.ID] = room
+		m.mu.Unlock()
+
+		room.mu.Lock()
+		for _, group := range []map[string]*Player{room.Players, room.Spectators} {
+			for _, p := range group {
+				if p.Type == PlayerHuman {
+					room.holdSeat(connEvent{player: p})
+				}
+			}
+		}
+		room.scheduleBotTurn()
+		room.mu.Unlock()
+		go room.Run()
+
+		if err := store.Delete(room.ID); err != nil {
+			errs = append(errs, err)
+		}
+		restored++
+		log.Printf("[Room %s] Restored from snapshot saved %s", room.ID, s.SavedAt.Format(time.RFC3339))
+	}
+	return restored, errors.Join(errs...)
+}
@@ -0,0 +1,95 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SnapshotStore keeps room snapshots between server runs. LoadAll returns
+// every snapshot it could read, along with errors for any it couldn't.
+type SnapshotStore interface {
+	Save(s RoomSnapshot) error
+	LoadAll() ([]RoomSnapshot, error)
+	Delete(roomID string) error
+}
+
+// FileSnapshotStore keeps each room's snapshot as <Dir>/<room id>.json. It
+// suits a single server with a local disk.
+type FileSnapshotStore struct {
+	Dir string
+}
+
+func NewFileSnapshotStore(dir string) *FileSnapshotStore {
+	return &FileSnapshotStore{Dir: dir}
+}
+
+func (fs *FileSnapshotStore) path(roomID string) string {
+	// Room IDs come from clients; keep them inside Dir
+	return filepath.Join(fs.Dir, filepath.Base(roomID)+".json")
+}
+
+// Save writes s, replacing any earlier snapshot of the room in one step.
+func (fs *FileSnapshotStore) Save(s RoomSnapshot) error {
+	if err := os.MkdirAll(fs.Dir, 0755); err != nil {
+		return err
+	}
+	return writeJSONFile(fs.path(s.ID), s)
+}
+
+// writeJSONFile writes v to path, replacing any earlier file in one step.
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// LoadAll reads every snapshot in Dir. A missing Dir means there are none;
+// an unreadable or corrupt file is skipped and reported.
+func (fs *FileSnapshotStore) LoadAll() ([]RoomSnapshot, error) {
+	entries, err := os.ReadDir(fs.Dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var snaps []RoomSnapshot
+	var errs []error
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(fs.Dir, e.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		var s RoomSnapshot
+		if err := json.Unmarshal(data, &s); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		snaps = append(snaps, s)
+	}
+	return snaps, errors.Join(errs...)
+}
+
+func (fs *FileSnapshotStore) Delete(roomID string) error {
+	err := os.Remove(fs.path(roomID))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,129 @@
+package game
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// snapshotManager returns a manager whose default pack is dict's file.
+func snapshotManager(t *testing.T, dict *Dictionary) *Manager {
+	t.Helper()
+	packs, err := LoadPacks(dict.path, filepath.Join(t.TempDir(), "none"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewManager(packs, nil)
+}
+
+// roundTrip saves s as JSON and restores a room from it.
+func roundTrip(t *testing.T, m *Manager, s RoomSnapshot) *Room {
+	t.Helper()
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved RoomSnapshot
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	r, err := m.restoreRoom(saved)
+	if err != nil {
+		t.Fatalf("restoreRoom() = %v", err)
+	}
+	t.Cleanup(func() { r.Close("test over") })
+	return r
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	dict := testDictionary(t, PlaceInfo{Name: "Oslo", Type: "City"}, PlaceInfo{Name: "Ottawa", Type: "City"})
+	m := snapshotManager(t, dict)
+	r, players := testRoom(t, dict, "ann", "bob")
+	r.Options.Passcode = "sesame"
+	r.startGame()
+	r.processTurn("ann", "oslo", "")
+	r.handleChatMessage(action(t, "CHAT", "bob", map[string]string{"message": "nice"}))
+
+	got := roundTrip(t, m, r.Snapshot())
+
+	if got.State != StatePlaying || got.LastWord != "Oslo" || !got.UsedWords["oslo"] {
+		t.Errorf("restored game: state %s, last word %q, used %v", got.State, got.LastWord, got.UsedWords)
+	}
+	if !reflect.DeepEqual(got.TurnOrder, r.TurnOrder) || got.CurrentTurnIndex != r.CurrentTurnIndex || got.Round != r.Round {
+		t.Errorf("restored turn: order %v index %d round %d, want %v %d %d", got.TurnOrder, got.CurrentTurnIndex, got.Round, r.TurnOrder, r.CurrentTurnIndex, r.Round)
+	}
+	if got.HostID != "ann" || got.Options.Passcode != "sesame" {
+		t.Errorf("restored host %q, passcode %q", got.HostID, got.Options.Passcode)
+	}
+	if len(got.History) != 1 || len(got.ChatHistory) != 1 {
+		t.Errorf("restored %d moves and %d chat messages, want 1 of each", len(got.History), len(got.ChatHistory))
+	}
+	if got.replay == nil || len(got.replay.Events) != len(r.replay.Events) {
+		t.Errorf("the game's recording so far wasn't restored")
+	}
+	for _, want := range players {
+		p, ok := got.FindByResumeToken(want.ResumeToken)
+		if !ok {
+			t.Errorf("%s can't resume with their token", want.Name)
+			continue
+		}
+		if p.Score != want.Score || p.Lives != want.Lives || p.IsTurn != want.IsTurn || p.Connected {
+			t.Errorf("restored %s = %+v, want %+v, disconnected", want.Name, p, want)
+		}
+	}
+
+	// The restored room plays on from where it was
+	got.processTurn("bob", "Ottawa", "")
+	if got.LastWord != "Ottawa" || got.Players["bob"].Score != 10 {
+		t.Errorf("restored game didn't accept the next move")
+	}
+}
+
+func TestSnapshotRestoresClocks(t *testing.T) {
+	dict := testDictionary(t, PlaceInfo{Name: "Oslo", Type: "City"})
+	m := snapshotManager(t, dict)
+	r, _ := testRoom(t, dict, "ann", "bob")
+	r.Mode = "POINT_RUSH"
+	r.startGame()
+
+	s := r.Snapshot()
+	s.TurnTimeRemainingMs = 5000
+	s.GameTimeRemainingMs = 60000
+	got := roundTrip(t, m, s)
+
+	if left := time.Until(got.TurnDeadline); left <= 4*time.Second || left > 5*time.Second {
+		t.Errorf("restored turn has %s left, want the 5s it was saved with", left)
+	}
+	if elapsed := time.Since(got.TurnStartTime); elapsed < 24*time.Second || elapsed > 26*time.Second {
+		t.Errorf("restored turn started %s ago, want 25s", elapsed)
+	}
+	if left := time.Until(got.GameDeadline); left <= 59*time.Second || left > 60*time.Second {
+		t.Errorf("restored game clock has %s left, want 60s", left)
+	}
+}
+
+func TestSnapshotRestoresTimedOutGame(t *testing.T) {
+	dict := testDictionary(t, PlaceInfo{Name: "Oslo", Type: "City"})
+	m := snapshotManager(t, dict)
+	r, _ := testRoom(t, dict, "ann", "bob")
+	r.Mode = "POINT_RUSH"
+	r.startGame()
+	r.processTurn("ann", "Oslo", "")
+
+	// Saved right at the game deadline
+	s := r.Snapshot()
+	s.GameTimeRemainingMs = 0
+	got := roundTrip(t, m, s)
+
+	if got.State != StateEnded {
+		t.Fatalf("restored state = %s, want the game over on time", got.State)
+	}
+	if got.lastReplayID == "" {
+		t.Errorf("the finished game's replay wasn't saved")
+	}
+	if got.turnTimer != nil || got.gameTimer != nil {
+		t.Errorf("a finished game still has clocks running")
+	}
+}
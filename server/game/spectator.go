@@ -0,0 +1,62 @@
+package game
+
+import (
+	"log"
+	"slices"
+)
+
+// spectatorActions are the only actions a spectator may send.
+var spectatorActions = map[string]bool{
+	"CHAT":           true,
+	"JOIN_AS_PLAYER": true,
+}
+
+// member finds someone in the room by ID, whether playing or spectating. Must
+// be called with r.mu held.
+func (r *Room) member(id string) (*Player, bool) {
+	if p, ok := r.Players[id]; ok {
+		return p, true
+	}
+	p, ok := r.Spectators[id]
+	return p, ok
+}
+
+// setSpectator moves a human between playing and watching. Switching is only
+// allowed between games, so nobody can dodge a loss or drop in with fresh
+// lives.
+func (r *Room) setSpectator(playerID string, spectate bool) {
+	r.mu.Lock()
+	p, ok := r.member(playerID)
+	if !ok || p.Type != PlayerHuman || p.Spectator == spectate {
+		r.mu.Unlock()
+		return
+	}
+	if r.State == StatePlaying {
+		r.sendError(playerID, "You can only switch between playing and watching between games")
+		r.mu.Unlock()
+		return
+	}
+
+	if spectate {
+		r.leaveGame(p)
+		p.Spectator = true
+		p.IsTurn = false
+		r.Spectators[p.ID] = p
+		log.Printf("[Room %s] %s is now spectating", r.ID, p.Name)
+	} else {
+		// Someone queued for the next game already holds a seat
+		if !slices.Contains(r.lateJoiners, p.ID) && r.seatsFull() {
+			r.sendError(playerID, errRoomFull.Reason)
+			r.mu.Unlock()
+			return
+		}
+		delete(r.Spectators, p.ID)
+		r.dequeue(p.ID)
+		p.Spectator = false
+		r.Players[p.ID] = p
+		r.TurnOrder = append(r.TurnOrder, p.ID)
+		log.Printf("[Room %s] %s joined as a player", r.ID, p.Name)
+	}
+	r.mu.Unlock()
+	r.broadcastState()
+}
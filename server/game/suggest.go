@@ -0,0 +1,129 @@
+package game
+
+import (
+	"sort"
+)
+
+// maxSuggestDistance bounds how far a suggestion may be from what the player
+// typed; anything further is not a plausible typo.
+const maxSuggestDistance = 3
+
+type Suggestion struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Distance int    `json:"distance"`
+}
+
+// Suggest returns up to limit places closest to word by edit distance over
+// normalized names (aliases included), nearest first. Only names sharing the
+// typed word's first letter are considered, since players rarely get that
+// wrong. Ties go to places whose canonical name shares it too, then
+// alphabetical order.
+func (d *Dictionary) Suggest(word string, limit int) []Suggestion {
+	return d.SuggestIn(word, limit, nil, PlaceFilter{})
+}
+
+// SuggestIn is Suggest for places still playable in a game: those in used
+// and those that don't count under filter are never suggested.
+func (d *Dictionary) SuggestIn(word string, limit int, used map[string]bool, filter PlaceFilter) []Suggestion {
+	target := []rune(NormalizePlace(word))
+	if len(target) == 0 || limit <= 0 {
+		return nil
+	}
+	first := target[0]
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	best := make(map[string]int) // canonical key -> distance
+	consider := func(key, canonical string) {
+		if used[canonical] {
+			return
+		}
+		candidate := []rune(key)
+		if abs(len(candidate)-len(target)) > maxSuggestDistance {
+			return
+		}
+		dist := editDistance(target, candidate, maxSuggestDistance)
+		if dist > maxSuggestDistance {
+			return
+		}
+		if prev, ok := best[canonical]; !ok || dist < prev {
+			best[canonical] = dist
+		}
+	}
+	for _, key := range d.byFirst[first] {
+		consider(key, key)
+	}
+	for _, alias := range d.aliasesByFirst[first] {
+		consider(alias, d.aliases[alias])
+	}
+
+	suggestions := make([]Suggestion, 0, len(best))
+	for key, dist := range best {
+		allowed := filter.Apply(d.places[key])
+		if len(allowed) == 0 {
+			continue
+		}
+		info := allowed[0]
+		suggestions = append(suggestions, Suggestion{Name: info.Name, Type: info.Type, Distance: dist})
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if a.Distance != b.Distance {
+			return a.Distance < b.Distance
+		}
+		aFirst, bFirst := FirstLetter(a.Name) == first, FirstLetter(b.Name) == first
+		if aFirst != bFirst {
+			return aFirst
+		}
+		return a.Name < b.Name
+	})
+
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
+
+// editDistance is the Damerau-Levenshtein (optimal string alignment) distance
+// between a and b, so a swapped pair of letters counts as one typo. It works
+// on runes, so a letter that survives normalization as several bytes is
+// still one edit. It gives up early and returns max+1 once every alignment
+// exceeds max.
+func editDistance(a, b []rune, max int) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > max {
+			return max + 1
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(b)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
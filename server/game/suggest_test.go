@@ -0,0 +1,146 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		max  int
+		want int
+	}{
+		{"paris", "paris", 3, 0},
+		{"paris", "pars", 3, 1},
+		{"paris", "parris", 3, 1},
+		{"paris", "parus", 3, 1},
+		{"paris", "pairs", 3, 1}, // Swapped pair
+		{"łodz", "lodz", 3, 1},   // One letter, though two bytes
+		{"москва", "мосвка", 3, 1},
+		{"", "oslo", 5, 4},
+		{"oslo", "toronto", 2, 3}, // Gives up past max
+	}
+	for _, tt := range tests {
+		if got := editDistance([]rune(tt.a), []rune(tt.b), tt.max); got != tt.want {
+			t.Errorf("editDistance(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	dict := testDictionary(t,
+		PlaceInfo{Name: "Paris", Type: "City"},
+		PlaceInfo{Name: "Parma", Type: "City"},
+		PlaceInfo{Name: "Perth", Type: "City"},
+		PlaceInfo{Name: "Łódź", Type: "City"},
+		PlaceInfo{Name: "Mumbai", Type: "City", Aliases: []string{"Bombay"}},
+		PlaceInfo{Name: "Aris", Type: "City"},
+	)
+	names := func(ss []Suggestion) []string {
+		var out []string
+		for _, s := range ss {
+			out = append(out, s.Name)
+		}
+		return out
+	}
+
+	tests := []struct {
+		word  string
+		limit int
+		want  []string
+	}{
+		{"Pariss", 1, []string{"Paris"}},
+		{"Parsi", 3, []string{"Paris", "Parma", "Perth"}},
+		{"Łodż", 1, []string{"Łódź"}},
+		{"Bombey", 1, []string{"Mumbai"}}, // Through its alias
+		{"Pxxxxxxx", 3, nil},              // Too far from anything
+		{"Xaris", 3, nil},                 // First letter must match
+		{"Paris", 0, nil},                 // No room for any
+		{"!!", 3, nil},                    // Nothing left to compare
+	}
+	for _, tt := range tests {
+		got := names(dict.Suggest(tt.word, tt.limit))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Suggest(%q, %d) = %v, want %v", tt.word, tt.limit, got, tt.want)
+		}
+	}
+
+	got := dict.Suggest("Parus", 5)
+	for _, s := range got {
+		if s.Distance > maxSuggestDistance {
+			t.Errorf("Suggest(Parus) returned %s at distance %d, past the limit of %d", s.Name, s.Distance, maxSuggestDistance)
+		}
+	}
+	if len(got) == 0 || got[0].Name != "Paris" || got[0].Distance != 1 {
+		t.Errorf("Suggest(Parus) = %v, want Paris at distance 1 first", got)
+	}
+}
+
+func TestSuggestIn(t *testing.T) {
+	dict := testDictionary(t,
+		PlaceInfo{Name: "Paris", Type: "City", CountryCode: "FR"},
+		PlaceInfo{Name: "Parma", Type: "City", CountryCode: "IT"},
+		PlaceInfo{Name: "Perth", Type: "City", CountryCode: "AU"},
+		PlaceInfo{Name: "Georgia", Type: "Country"},
+		PlaceInfo{Name: "Georgia", Type: "State", CountryCode: "US"},
+	)
+
+	tests := []struct {
+		name   string
+		word   string
+		used   []string
+		filter PlaceFilter
+		want   []Suggestion
+	}{
+		{"nothing excluded", "Parsi", nil, PlaceFilter{}, []Suggestion{{"Paris", "City", 1}, {"Parma", "City", 2}, {"Perth", "City", 3}}},
+		{"nearest already used", "Parsi", []string{"paris"}, PlaceFilter{}, []Suggestion{{"Parma", "City", 2}, {"Perth", "City", 3}}},
+		{"outside the filter", "Parsi", nil, PlaceFilter{Countries: []string{"IT", "AU"}}, []Suggestion{{"Parma", "City", 2}, {"Perth", "City", 3}}},
+		{"used and filtered", "Parsi", []string{"parma"}, PlaceFilter{Countries: []string{"IT", "AU"}}, []Suggestion{{"Perth", "City", 3}}},
+		{"the entry that counts", "Georgai", nil, PlaceFilter{Types: []string{"State"}}, []Suggestion{{"Georgia", "State", 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			used := make(map[string]bool)
+			for _, key := range tt.used {
+				used[key] = true
+			}
+			if got := dict.SuggestIn(tt.word, 3, used, tt.filter); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SuggestIn(%q) = %v, want %v", tt.word, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAutoCorrect(t *testing.T) {
+	dict := testDictionary(t,
+		PlaceInfo{Name: "Paris", Type: "City"},
+		PlaceInfo{Name: "Lima", Type: "City"},
+		PlaceInfo{Name: "Lina", Type: "City"},
+	)
+	r := NewRoom("test", dict, nil)
+
+	if _, ok := r.autoCorrect("Pariss"); ok {
+		t.Errorf("autoCorrect corrected with the setting off")
+	}
+	r.Settings["autoCorrect"] = 1
+	if got, ok := r.autoCorrect("Pariss"); !ok || got.Name != "Paris" {
+		t.Errorf("autoCorrect(Pariss) = %v, %v; want Paris", got, ok)
+	}
+	if got, ok := r.autoCorrect("Parisss"); ok {
+		t.Errorf("autoCorrect(Parisss) = %v, want nothing past the threshold", got)
+	}
+	if got, ok := r.autoCorrect("Lixa"); ok {
+		t.Errorf("autoCorrect(Lixa) = %v, want nothing when Lima and Lina tie", got)
+	}
+
+	// A used place can't be played, so it's never the correction
+	r.UsedWords["paris"] = true
+	if got, ok := r.autoCorrect("Pariss"); ok {
+		t.Errorf("autoCorrect(Pariss) = %v, want nothing once Paris is used", got)
+	}
+	r.UsedWords["lina"] = true
+	if got, ok := r.autoCorrect("Lixa"); !ok || got.Name != "Lima" {
+		t.Errorf("autoCorrect(Lixa) = %v, %v; want Lima once Lina is used", got, ok)
+	}
+}
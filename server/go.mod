@@ -5,13 +5,10 @@ go 1.24.0
 toolchain go1.24.11
 
 require (
+	github.com/LindsayBradford/go-dbf v1.0.0-aplha.4
 	github.com/google/uuid v1.6.0
 	github.com/gorilla/websocket v1.5.3
-	github.com/mr-destructive/meta-ai-golang v0.0.0-20240829172319-e26cef604cc2
+	golang.org/x/text v0.32.0
 )
 
-require (
-	github.com/LindsayBradford/go-dbf v1.0.0-aplha.4 // indirect
-	github.com/axgle/mahonia v0.0.0-20180208002826-3358181d7394 // indirect
-	golang.org/x/text v0.32.0 // indirect
-)
+require github.com/axgle/mahonia v0.0.0-20180208002826-3358181d7394 // indirect
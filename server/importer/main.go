@@ -7,18 +7,17 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"unicode"
 
 	"github.com/LindsayBradford/go-dbf/godbf"
-	"golang.org/x/text/runes"
-	"golang.org/x/text/transform"
-	"golang.org/x/text/unicode/norm"
+
+	"wa-1/game"
 )
 
 func toASCII(s string) string {
-	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
-	result, _, _ := transform.String(t, s)
+	result := game.StripAccents(s)
 	
 	// Further cleanup: remove any remaining non-ASCII characters and filter to just letters, spaces, hyphens
 	var b strings.Builder
@@ -31,34 +30,75 @@ func toASCII(s string) string {
 }
 
 type Place struct {
-	Name string `json:"name"`
-	Type string `json:"type"`
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Aliases     []string `json:"aliases,omitempty"`
+	CountryCode string   `json:"countryCode,omitempty"`
+	Admin       string   `json:"admin,omitempty"`
+	Lat         float64  `json:"lat,omitempty"`
+	Lon         float64  `json:"lon,omitempty"`
+	Population  int64    `json:"population,omitempty"`
+	Capital     bool     `json:"capital,omitempty"`
+}
+
+// source is one Natural Earth table and the columns that feed each Place
+// field. Metadata columns are optional; blank ones are skipped.
+type source struct {
+	SubPath    string
+	File       string
+	Col        string
+	AliasCols  []string
+	Type       string
+	CountryCol string
+	AdminCol   string
+	LatCol     string
+	LonCol     string
+	PopCol     string
+	ClassCol   string // Feature class; "Admin-0 capital" marks national capitals
+}
+
+// Common abbreviations expanded into aliases, e.g. "St Petersburg" is also
+// accepted as "Saint Petersburg".
+var abbreviations = map[string]string{
+	"St":  "Saint",
+	"Ste": "Sainte",
+	"Ft":  "Fort",
+	"Mt":  "Mount",
 }
 
 func main() {
 	base := "/home/meet/code/Natural_Earth_quick_start/packages/Natural_Earth_quick_start"
 	outputFile := "../data/places.json"
 
-	// Map Name -> Type (Country overrides State, State overrides City if duplicate names exist, or keep all? 
-	// For simplicity, let's keep the "highest" level (Country > State > City)
-	placeMap := make(map[string]string)
+	// Map Name+Type -> Place. A name shared by different kinds of place
+	// ("Georgia" the Country and the State) keeps one entry per type; the
+	// game lets players say which one they meant.
+	placeMap := make(map[placeKey]*Place)
 
-	sources := []struct {
-		SubPath string
-		File    string
-		Col     string
-		Type    string
-	}{
+	sources := []source{
 		// 10m - High resolution
-		{"10m_cultural", "ne_10m_populated_places.dbf", "NAME", "City"},
-		{"10m_cultural", "ne_10m_admin_1_states_provinces.dbf", "name", "State"}, 
-		{"10m_cultural", "ne_10m_admin_0_countries.dbf", "NAME", "Country"},
+		{
+			SubPath: "10m_cultural", File: "ne_10m_populated_places.dbf", Col: "NAME",
+			AliasCols: []string{"NAMEASCII", "NAMEALT", "NAME_EN"}, Type: "City",
+			CountryCol: "ISO_A2", AdminCol: "ADM1NAME", LatCol: "LATITUDE", LonCol: "LONGITUDE", PopCol: "POP_MAX",
+			ClassCol: "FEATURECLA",
+		},
+		{
+			SubPath: "10m_cultural", File: "ne_10m_admin_1_states_provinces.dbf", Col: "name",
+			AliasCols: []string{"name_alt", "name_en", "woe_name", "gn_name"}, Type: "State",
+			CountryCol: "iso_a2", AdminCol: "admin", LatCol: "latitude", LonCol: "longitude",
+		},
+		{
+			SubPath: "10m_cultural", File: "ne_10m_admin_0_countries.dbf", Col: "NAME",
+			AliasCols: []string{"NAME_LONG", "NAME_EN", "FORMAL_EN", "NAME_SORT", "NAME_ALT"}, Type: "Country",
+			CountryCol: "ISO_A2_EH", AdminCol: "CONTINENT", LatCol: "LABEL_Y", LonCol: "LABEL_X", PopCol: "POP_EST",
+		},
 	}
 
 	for _, src := range sources {
 		fullPath := filepath.Join(base, src.SubPath, src.File)
 		
-		err := readDBF(fullPath, src.Col, src.Type, placeMap)
+		err := readDBF(fullPath, src, placeMap)
 		if err != nil {
 			if os.IsNotExist(err) {
 				fmt.Printf("Skipping %s: File not found.\n", src.Type)
@@ -72,15 +112,19 @@ func main() {
 
 	// Convert to List
 	var finalList []Place
-	for name, pType := range placeMap {
-		if len(name) > 1 {
-			finalList = append(finalList, Place{Name: name, Type: pType})
+	for key, place := range placeMap {
+		if len(key.Name) > 1 {
+			sort.Strings(place.Aliases)
+			finalList = append(finalList, *place)
 		}
 	}
 	
 	// Sort by name
 	sort.Slice(finalList, func(i, j int) bool {
-		return finalList[i].Name < finalList[j].Name
+		if finalList[i].Name != finalList[j].Name {
+			return finalList[i].Name < finalList[j].Name
+		}
+		return finalList[i].Type < finalList[j].Type
 	})
 
 	data, err := json.MarshalIndent(finalList, "", "  ")
@@ -95,23 +139,55 @@ func main() {
 	fmt.Printf("Successfully wrote %d places to %s\n", len(finalList), outputFile)
 }
 
-func readDBF(path string, colName string, typeName string, storage map[string]string) error {
+type placeKey struct {
+	Name string
+	Type string
+}
+
+func readDBF(path string, src source, storage map[placeKey]*Place) error {
 	dbfTable, err := godbf.NewFromFile(path, "UTF8")
 	if err != nil {
 		return err
 	}
 
-	colIdx := -1
 	fields := dbfTable.Fields()
-	for j, field := range fields {
-		if strings.EqualFold(field.Name(), colName) {
-			colIdx = j
-			break
+	findCol := func(name string) int {
+		if name == "" {
+			return -1
 		}
+		for j, field := range fields {
+			if strings.EqualFold(field.Name(), name) {
+				return j
+			}
+		}
+		return -1
 	}
 
+	typeName := src.Type
+	colIdx := findCol(src.Col)
 	if colIdx == -1 {
-		return fmt.Errorf("column '%s' not found", colName)
+		return fmt.Errorf("column '%s' not found", src.Col)
+	}
+
+	// Alias and metadata columns are optional; older Natural Earth releases lack some
+	var aliasIdx []int
+	for _, aliasCol := range src.AliasCols {
+		if j := findCol(aliasCol); j != -1 {
+			aliasIdx = append(aliasIdx, j)
+		}
+	}
+	countryIdx, adminIdx := findCol(src.CountryCol), findCol(src.AdminCol)
+	latIdx, lonIdx, popIdx := findCol(src.LatCol), findCol(src.LonCol), findCol(src.PopCol)
+	classIdx := findCol(src.ClassCol)
+	cell := func(row []string, j int) string {
+		if j == -1 || j >= len(row) {
+			return ""
+		}
+		v := strings.TrimSpace(row[j])
+		if v == "-99" { // Natural Earth's "no data" marker
+			return ""
+		}
+		return v
 	}
 
 	for i := 0; i < dbfTable.NumberOfRecords(); i++ {
@@ -125,24 +201,83 @@ func readDBF(path string, colName string, typeName string, storage map[string]st
 				// Actually the game logic lowercases everything. 
 				// Let's just store the Display Name.
 				
-				// Priority: Country > State > City
-				// If already exists...
-				currentType, exists := storage[val]
+				candidate := Place{
+					Name:        val,
+					Type:        typeName,
+					CountryCode: cell(row, countryIdx),
+					Admin:       toASCII(cell(row, adminIdx)),
+					Capital:     strings.Contains(strings.ToLower(cell(row, classIdx)), "admin-0 capital"),
+				}
+				candidate.Lat, _ = strconv.ParseFloat(cell(row, latIdx), 64)
+				candidate.Lon, _ = strconv.ParseFloat(cell(row, lonIdx), 64)
+				if pop, err := strconv.ParseFloat(cell(row, popIdx), 64); err == nil {
+					candidate.Population = int64(pop)
+				}
+
+				// Among same-type duplicates (the many Springfields)
+				// the most populous one supplies the metadata.
+				key := placeKey{Name: val, Type: typeName}
+				current, exists := storage[key]
 				if exists {
-					if typeName == "Country" {
-						storage[val] = typeName
-					} else if typeName == "State" && currentType == "City" {
-						storage[val] = typeName
+					if candidate.Population > current.Population {
+						candidate.Aliases = current.Aliases
+						*current = candidate
 					}
 				} else {
-					storage[val] = typeName
+					current = &candidate
+					storage[key] = current
+				}
+
+				for _, j := range aliasIdx {
+					if j < len(row) {
+						addAliases(current, row[j])
+					}
 				}
+				addAliases(current, expandAbbreviations(val))
 			}
 		}
 	}
 	return nil
 }
 
+// addAliases records every alternate name in raw (Natural Earth separates
+// multiple alternates with "|") that differs from the place's own name.
+func addAliases(p *Place, raw string) {
+	for _, part := range strings.Split(raw, "|") {
+		alias := clean(strings.TrimSpace(part))
+		if alias == "" || !isValid(alias) || strings.EqualFold(alias, p.Name) {
+			continue
+		}
+		known := false
+		for _, existing := range p.Aliases {
+			if strings.EqualFold(existing, alias) {
+				known = true
+				break
+			}
+		}
+		if !known {
+			p.Aliases = append(p.Aliases, alias)
+		}
+	}
+}
+
+// expandAbbreviations spells out abbreviated words in name, returning "" if
+// there is nothing to expand.
+func expandAbbreviations(name string) string {
+	words := strings.Fields(name)
+	changed := false
+	for i, w := range words {
+		if full, ok := abbreviations[w]; ok {
+			words[i] = full
+			changed = true
+		}
+	}
+	if !changed {
+		return ""
+	}
+	return strings.Join(words, " ")
+}
+
 func clean(s string) string {
 	if idx := strings.Index(s, "("); idx != -1 {
 		s = s[:idx]
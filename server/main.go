@@ -1,23 +1,47 @@
 package main
 
 import (
+	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
 	
 	"wa-1/game"
 )
 
 func main() {
-	// 1. Load Dictionary
-	dict, err := game.NewDictionary(filepath.Join("data", "places.json"))
+	// 1. Load Dictionary packs
+	dataPath := os.Getenv("DATA_PATH")
+	if dataPath == "" {
+		dataPath = filepath.Join("data", "places.json")
+	}
+	packsDir := os.Getenv("PACKS_DIR")
+	if packsDir == "" {
+		packsDir = filepath.Join("data", "packs")
+	}
+	packs, err := game.LoadPacks(dataPath, packsDir)
 	if err != nil {
 		log.Fatalf("Failed to load dictionary: %v", err)
 	}
-	log.Println("Dictionary loaded.")
+	log.Printf("Dictionary loaded. Packs: %v", packs.Names())
+
+	// Reload dictionaries on SIGHUP without dropping games
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			reloadPacks(packs)
+		}
+	}()
 
 	// 2. Setup User Manager
 	um, err := game.NewUserManager(filepath.Join("data", "users.json"))
@@ -26,13 +50,45 @@ func main() {
 	}
 	log.Println("User Manager loaded.")
 
-	// 3. Setup Game Manager
-	manager := game.NewManager(dict, um)
+	// 3. Setup Game Manager, bringing back games saved at the last shutdown
+	manager := game.NewManager(packs, um)
+	replayDir := os.Getenv("REPLAY_DIR")
+	if replayDir == "" {
+		replayDir = filepath.Join("data", "replays")
+	}
+	manager.Replays = game.NewFileReplayStore(replayDir)
+	snapshotDir := os.Getenv("SNAPSHOT_DIR")
+	if snapshotDir == "" {
+		snapshotDir = filepath.Join("data", "snapshots")
+	}
+	snapshots := game.NewFileSnapshotStore(snapshotDir)
+	n, err := manager.RestoreRooms(snapshots)
+	if err != nil {
+		log.Printf("Restoring rooms: %v", err)
+	}
+	if n > 0 {
+		log.Printf("Restored %d rooms.", n)
+	}
+	idleTTL := game.DefaultRoomIdleTTL
+	if secs, err := strconv.Atoi(os.Getenv("ROOM_IDLE_TTL_SECONDS")); err == nil && secs > 0 {
+		idleTTL = time.Duration(secs) * time.Second
+	}
+	manager.StartReaper(idleTTL)
+	if n, err := strconv.Atoi(os.Getenv("MATCH_SIZE")); err == nil && n >= 2 && n <= game.MaxPlayersLimit {
+		manager.Matchmaker.RoomSize = n
+	}
+	if secs, err := strconv.Atoi(os.Getenv("MATCH_BOT_WAIT_SECONDS")); err == nil && secs >= 0 {
+		manager.Matchmaker.BotWait = time.Duration(secs) * time.Second
+	}
 
 	// 4. Setup Routes
 	// Handle API routes specifically to avoid conflict with file server catch-all
 	http.HandleFunc("/api/register", handleRegister(um))
 	http.HandleFunc("/api/login", handleLogin(um))
+	http.HandleFunc("/api/admin/reload", handleReload(packs))
+	http.HandleFunc("/api/rooms", handleListRooms(manager))
+	http.HandleFunc("/api/rooms/{id}", handleGetRoom(manager))
+	http.HandleFunc("/api/games/{id}", handleGetGame(manager))
 	http.HandleFunc("/ws", manager.HandleWS)
 	
 	// Serve Frontend (Vue build)
@@ -46,10 +102,34 @@ func main() {
 	}
 	
 	addr := fmt.Sprintf(":%s", port)
-	log.Printf("Server starting on %s", addr)
-	if err := http.ListenAndServe(addr, nil); err != nil {
-		log.Fatal(err)
+	srv := &http.Server{Addr: addr}
+	go func() {
+		log.Printf("Server starting on %s", addr)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
+
+	// 5. Shut down gracefully on SIGINT/SIGTERM: warn players, let games
+	// finish within the grace period and save the rest
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+
+	grace := 20 * time.Second
+	if secs, err := strconv.Atoi(os.Getenv("SHUTDOWN_GRACE_SECONDS")); err == nil && secs >= 0 {
+		grace = time.Duration(secs) * time.Second
+	}
+	if err := manager.Shutdown(grace, snapshots); err != nil {
+		log.Printf("Shutdown: %v", err)
 	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("HTTP shutdown: %v", err)
+	}
+	log.Println("Server stopped")
 }
 
 type AuthRequest struct {
@@ -117,6 +197,108 @@ func handleLogin(um *game.UserManager) http.HandlerFunc {
 	}
 }
 
+// handleListRooms lists public rooms for the room browser.
+func handleListRooms(manager *game.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		enableCors(&w)
+		if r.Method == "OPTIONS" { return }
+
+		if r.Method != "GET" {
+			respondJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"rooms": manager.ListRooms()})
+	}
+}
+
+func handleGetRoom(manager *game.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		enableCors(&w)
+		if r.Method == "OPTIONS" { return }
+
+		if r.Method != "GET" {
+			respondJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		room, ok := manager.RoomSummary(r.PathValue("id"))
+		if !ok {
+			respondJSONError(w, "Room not found", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(room)
+	}
+}
+
+// handleGetGame returns a finished game's replay.
+func handleGetGame(manager *game.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		enableCors(&w)
+		if r.Method == "OPTIONS" { return }
+
+		if r.Method != "GET" {
+			respondJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		replay, err := manager.GetReplay(r.PathValue("id"))
+		if errors.Is(err, game.ErrReplayNotFound) {
+			respondJSONError(w, "Game not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			respondJSONError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(replay)
+	}
+}
+
+func reloadPacks(packs *game.PackSet) error {
+	if err := packs.Reload(); err != nil {
+		log.Printf("Dictionary reload failed: %v", err)
+		return err
+	}
+	log.Printf("Dictionary reloaded. Places per pack: %v", packs.Sizes())
+	return nil
+}
+
+// handleReload reloads every data pack. It is only enabled when ADMIN_TOKEN
+// is set, and requires it as a bearer token.
+func handleReload(packs *game.PackSet) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := os.Getenv("ADMIN_TOKEN")
+		if token == "" {
+			respondJSONError(w, "Not found", http.StatusNotFound)
+			return
+		}
+
+		if r.Method != "POST" {
+			respondJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+token)) != 1 {
+			respondJSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		if err := reloadPacks(packs); err != nil {
+			respondJSONError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"packs": packs.Names(), "places": packs.Sizes()})
+	}
+}
+
 func enableCors(w *http.ResponseWriter) {
 	(*w).Header().Set("Access-Control-Allow-Origin", "*")
 	(*w).Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")